@@ -0,0 +1,231 @@
+package okta
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	oktav4 "github.com/okta/okta-sdk-golang/v4/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Default number of seconds the full listings of users, groups and
+// applications are cached for
+const defaultListCacheTtl = 300
+
+// map of connection name and cache key to the *sync.Mutex held while the
+// listing is fetched, so that concurrent hydrate calls only fetch it once
+var listCacheLocks sync.Map
+
+// listCacheTtl returns how long full listings are cached for, as set by the
+// list_cache_ttl config argument.
+func listCacheTtl(d *plugin.QueryData) time.Duration {
+	ttl := int64(defaultListCacheTtl)
+	if t := GetConfig(d.Connection).ListCacheTtl; t != nil {
+		ttl = *t
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// getCachedList returns the items cached under key in the connection cache,
+// calling list to fetch and cache them if they aren't cached yet.
+func getCachedList[T any](ctx context.Context, d *plugin.QueryData, key string, list func() ([]T, error)) ([]T, error) {
+	return getCachedValue(ctx, d, key, list)
+}
+
+// getCachedValue returns the value cached under key in the connection cache,
+// calling fetch to fetch and cache it if it isn't cached yet.
+//
+// Memoize isn't used since its TTL is set when the function is declared,
+// whereas this TTL is set per connection.
+func getCachedValue[T any](ctx context.Context, d *plugin.QueryData, key string, fetch func() (T, error)) (T, error) {
+	ttl := listCacheTtl(d)
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	if cachedData, ok := d.ConnectionCache.Get(ctx, key); ok {
+		return cachedData.(T), nil
+	}
+
+	lock, _ := listCacheLocks.LoadOrStore(d.Connection.Name+"/"+key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Another hydrate call may have fetched the value while waiting for the lock
+	if cachedData, ok := d.ConnectionCache.Get(ctx, key); ok {
+		return cachedData.(T), nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if err := d.ConnectionCache.SetWithTTL(ctx, key, value, ttl); err != nil {
+		plugin.Logger(ctx).Warn("getCachedValue", "key", key, "cache_error", err)
+	}
+
+	return value, nil
+}
+
+// isListCached returns true if the items are already cached under key.
+func isListCached(ctx context.Context, d *plugin.QueryData, key string) bool {
+	if listCacheTtl(d) <= 0 {
+		return false
+	}
+	_, ok := d.ConnectionCache.Get(ctx, key)
+	return ok
+}
+
+// streamCachedList streams the items cached under key, fetching them with a
+// paginator created by newPaginator first if needed. Queries with a limit
+// stream directly from the API unless the items are already cached, since
+// fetching every item would defeat the limit. The limit only sets the page
+// size if the items are the rows of the table, since the limit of a child
+// table applies to its own rows rather than the parent items.
+func streamCachedList[T any](ctx context.Context, d *plugin.QueryData, key string, maxPageSize int64, newPaginator func(pageSize int64) *paginator[T]) error {
+	if d.QueryContext.Limit != nil && !isListCached(ctx, d, key) {
+		size := maxPageSize
+		if d.Table.List.ParentHydrate == nil {
+			size = pageSize(d, maxPageSize)
+		}
+		return newPaginator(size).Stream(ctx)
+	}
+
+	items, err := getCachedList(ctx, d, key, func() ([]T, error) {
+		return newPaginator(maxPageSize).All(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		d.StreamListItem(ctx, item)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+//// PARENT HYDRATE FUNCTIONS
+
+// listCachedOktaUsers streams every user of the org from the list cache. It is
+// the parent hydrate of tables with one or more rows per user.
+func listCachedOktaUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("listCachedOktaUsers", "connect_error", err)
+		return nil, err
+	}
+
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/reference/api/users/#request-parameters-3
+	err = streamCachedList(ctx, d, "listCachedOktaUsers", 200, func(pageSize int64) *paginator[*okta.User] {
+		return newPaginatorV2(d, "listCachedOktaUsers", func() ([]*okta.User, *okta.Response, error) {
+			return client.User.ListUsers(ctx, &query.Params{Limit: pageSize})
+		})
+	})
+	return nil, err
+}
+
+// listCachedOktaGroups streams every group of the org from the list cache. It
+// is the parent hydrate of tables with one or more rows per group.
+func listCachedOktaGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("listCachedOktaGroups", "connect_error", err)
+		return nil, err
+	}
+
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/reference/api/groups/#list-groups
+	err = streamCachedList(ctx, d, "listCachedOktaGroups", 10000, func(pageSize int64) *paginator[*okta.Group] {
+		return newPaginatorV2(d, "listCachedOktaGroups", func() ([]*okta.Group, *okta.Response, error) {
+			return client.Group.ListGroups(ctx, &query.Params{Limit: pageSize})
+		})
+	})
+	return nil, err
+}
+
+// listCachedOktaApplications streams every application of the org from the
+// list cache. It is the parent hydrate of tables with one or more rows per
+// application.
+func listCachedOktaApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("listCachedOktaApplications", "connect_error", err)
+		return nil, err
+	}
+
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/reference/api/apps/#list-applications
+	err = streamCachedList(ctx, d, "listCachedOktaApplications", 200, func(pageSize int64) *paginator[*okta.Application] {
+		return newPaginatorV2(d, "listCachedOktaApplications", func() ([]*okta.Application, *okta.Response, error) {
+			return listApplications(ctx, client, &query.Params{Limit: pageSize})
+		})
+	})
+	return nil, err
+}
+
+// getCachedOktaGroups returns every group of the org from the list cache, so
+// that groups referenced by ID can be resolved in-process.
+func getCachedOktaGroups(ctx context.Context, d *plugin.QueryData) ([]*okta.Group, error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return getCachedList(ctx, d, "listCachedOktaGroups", func() ([]*okta.Group, error) {
+		paginator := newPaginatorV2(d, "listCachedOktaGroups", func() ([]*okta.Group, *okta.Response, error) {
+			return client.Group.ListGroups(ctx, &query.Params{Limit: 10000})
+		})
+		return paginator.All(ctx)
+	})
+}
+
+// listCachedOktaUserTypes returns every user type of the org from the list
+// cache, so that the user type of each user can be resolved in-process.
+func listCachedOktaUserTypes(ctx context.Context, d *plugin.QueryData) ([]*okta.UserType, error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return getCachedList(ctx, d, "listCachedOktaUserTypes", func() ([]*okta.UserType, error) {
+		paginator := newPaginatorV2(d, "listCachedOktaUserTypes", func() ([]*okta.UserType, *okta.Response, error) {
+			return client.UserType.ListUserTypes(ctx)
+		})
+		userTypes, err := paginator.All(ctx)
+		if err != nil && isNotFoundError(err) {
+			return nil, nil
+		}
+		return userTypes, err
+	})
+}
+
+// listCachedOktaLinkedObjectDefinitions returns every linked object definition
+// of the org from the list cache.
+func listCachedOktaLinkedObjectDefinitions(ctx context.Context, d *plugin.QueryData) ([]oktav4.LinkedObject, error) {
+	client, err := ConnectV4(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return getCachedList(ctx, d, "listCachedOktaLinkedObjectDefinitions", func() ([]oktav4.LinkedObject, error) {
+		paginator := newPaginatorV4(d, "listCachedOktaLinkedObjectDefinitions", client.LinkedObjectAPI.ListLinkedObjectDefinitions(ctx).Execute)
+		definitions, err := paginator.All(ctx)
+		if err != nil && isNotFoundError(err) {
+			return nil, nil
+		}
+		return definitions, err
+	})
+}
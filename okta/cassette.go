@@ -0,0 +1,340 @@
+package okta
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	// Every HTTP exchange is written to the cassette directory
+	cassetteModeRecord = "record"
+	// Responses are served from the cassette directory, without any network
+	cassetteModeReplay = "replay"
+)
+
+var (
+	emailPattern          = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	pseudonymEmailPattern = regexp.MustCompile(`^user-[0-9a-f]{8}@example\.com$`)
+	linkURLPattern        = regexp.MustCompile(`<[^>]*>`)
+	cassetteNameFilter    = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+	// JSON properties holding secrets, replaced with a placeholder
+	cassetteTokenProperties = map[string]bool{
+		"access_token":  true,
+		"id_token":      true,
+		"refresh_token": true,
+		"token":         true,
+		"client_secret": true,
+		"sharedSecret":  true,
+		"password":      true,
+	}
+
+	// JSON properties holding the names of people, replaced with a pseudonym
+	cassetteNameProperties = map[string]bool{
+		"firstName":       true,
+		"lastName":        true,
+		"middleName":      true,
+		"displayName":     true,
+		"nickName":        true,
+		"honorificPrefix": true,
+		"honorificSuffix": true,
+		"login":           true,
+		"alternateId":     true,
+		"manager":         true,
+	}
+
+	// Headers that are never written to a cassette
+	cassetteDroppedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Okta-Request-Id"}
+)
+
+// cassetteExchange is a recorded HTTP exchange, stored as one JSON file per
+// request in the cassette directory.
+type cassetteExchange struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type cassetteResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header"`
+	Body       json.RawMessage     `json:"body,omitempty"`
+	BodyIsText bool                `json:"body_is_text,omitempty"`
+}
+
+// cassetteMode returns the cassette_mode config argument, or an error if it
+// is neither record nor replay.
+func cassetteMode(d *plugin.QueryData) (string, error) {
+	config := GetConfig(d.Connection)
+	if config.CassetteMode == nil || *config.CassetteMode == "" {
+		return "", nil
+	}
+	switch mode := *config.CassetteMode; mode {
+	case cassetteModeRecord, cassetteModeReplay:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid cassette_mode %q, must be one of %q or %q", mode, cassetteModeRecord, cassetteModeReplay)
+	}
+}
+
+// cassetteDir returns the directory of the cassette of the connection, set by
+// the cassette_dir config argument.
+func cassetteDir(d *plugin.QueryData) string {
+	if config := GetConfig(d.Connection); config.CassetteDir != nil && *config.CassetteDir != "" {
+		return *config.CassetteDir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("cassettes", d.Connection.Name)
+	}
+	return filepath.Join(home, ".steampipe", "internal", "okta", "cassettes", d.Connection.Name)
+}
+
+// cassetteTransport records HTTP exchanges to, or replays them from, a
+// cassette directory.
+type cassetteTransport struct {
+	base http.RoundTripper
+	mode string
+	dir  string
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.dir, cassetteFileName(req))
+
+	if t.mode == cassetteModeReplay {
+		return t.replay(req, path)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	// Rate limited responses are retried by the SDKs, so only the response
+	// of the retry is recorded
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	if err := t.record(req, resp, body, path); err != nil {
+		// Recording must not fail the query
+		requestLogger(req.Context()).Warn("cassetteTransport.RoundTrip", "method", req.Method, "path", req.URL.Path, "record_error", err)
+	}
+	return resp, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request, resp *http.Response, body []byte, path string) error {
+	exchange := cassetteExchange{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    scrubURL(req.URL).String(),
+		},
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     map[string][]string{},
+		},
+	}
+
+	for name, values := range resp.Header {
+		if isDroppedHeader(name) {
+			continue
+		}
+		scrubbed := make([]string, len(values))
+		for i, value := range values {
+			scrubbed[i] = scrubHeader(value)
+		}
+		exchange.Response.Header[name] = scrubbed
+	}
+
+	var decoded interface{}
+	if len(body) > 0 && json.Unmarshal(body, &decoded) == nil {
+		scrubbedBody, err := json.Marshal(scrubJSON(decoded, false))
+		if err != nil {
+			return err
+		}
+		exchange.Response.Body = scrubbedBody
+	} else if len(body) > 0 {
+		text, err := json.Marshal(scrubEmails(string(body)))
+		if err != nil {
+			return err
+		}
+		exchange.Response.Body = text
+		exchange.Response.BodyIsText = true
+	}
+
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (t *cassetteTransport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s in cassette %s: %v", req.Method, req.URL.RequestURI(), t.dir, err)
+	}
+
+	var exchange cassetteExchange
+	if err := json.Unmarshal(data, &exchange); err != nil {
+		return nil, fmt.Errorf("invalid cassette file %s: %v", path, err)
+	}
+
+	body := []byte(exchange.Response.Body)
+	if exchange.Response.BodyIsText {
+		var text string
+		if err := json.Unmarshal(exchange.Response.Body, &text); err != nil {
+			return nil, fmt.Errorf("invalid cassette file %s: %v", path, err)
+		}
+		body = []byte(text)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Response.StatusCode, http.StatusText(exchange.Response.StatusCode)),
+		StatusCode:    exchange.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(exchange.Response.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// cassetteFileName identifies a request by its method, path and query, e.g.
+// GET_api_v1_users_3f2a1b9c.json. The org host isn't part of it so that a
+// cassette can be replayed by any connection, and emails are scrubbed so that
+// the next page links of recorded responses lead to recorded requests.
+func cassetteFileName(req *http.Request) string {
+	u := scrubURL(req.URL)
+	hash := sha256.Sum256([]byte(req.Method + " " + u.Path + "?" + u.RawQuery))
+	name := strings.Trim(cassetteNameFilter.ReplaceAllString(u.Path, "_"), "_")
+	// Keep file names short enough for every file system
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return fmt.Sprintf("%s_%s_%s.json", req.Method, name, hex.EncodeToString(hash[:4]))
+}
+
+// scrubURL returns a copy of u with the emails in its path and query
+// parameters scrubbed. The query is re-encoded in canonical order.
+func scrubURL(u *url.URL) *url.URL {
+	scrubbed := *u
+	scrubbed.Path = scrubEmails(u.Path)
+	scrubbed.RawPath = ""
+
+	query := u.Query()
+	for key, values := range query {
+		for i, value := range values {
+			values[i] = scrubEmails(value)
+		}
+		query[key] = values
+	}
+	scrubbed.RawQuery = query.Encode()
+	return &scrubbed
+}
+
+// scrubHeader scrubs the emails in a header value, including in the URLs of
+// Link headers.
+func scrubHeader(value string) string {
+	value = linkURLPattern.ReplaceAllStringFunc(value, func(link string) string {
+		u, err := url.Parse(strings.Trim(link, "<>"))
+		if err != nil {
+			return link
+		}
+		return "<" + scrubURL(u).String() + ">"
+	})
+	return scrubEmails(value)
+}
+
+// scrubJSON replaces tokens, emails, the names of people and the profile names
+// of groups in a decoded JSON value. Pseudonyms are derived from the original value, so that the same
+// person has the same pseudonym in every recorded response.
+func scrubJSON(value interface{}, inProfile bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			s, isString := item.(string)
+			switch {
+			case isString && cassetteTokenProperties[key]:
+				v[key] = "REDACTED"
+			case isString && (cassetteNameProperties[key] || (inProfile && key == "name")):
+				v[key] = pseudonym(s)
+			default:
+				v[key] = scrubJSON(item, key == "profile")
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrubJSON(item, inProfile)
+		}
+		return v
+	case string:
+		return scrubEmails(v)
+	}
+	return value
+}
+
+// scrubEmails replaces every email address in s with a pseudonym. Pseudonyms
+// are left as is, so that scrubbing is idempotent.
+func scrubEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		if pseudonymEmailPattern.MatchString(email) {
+			return email
+		}
+		return "user-" + shortHash(strings.ToLower(email)) + "@example.com"
+	})
+}
+
+// pseudonym replaces a name, or a login that may be an email address.
+func pseudonym(s string) string {
+	if s == "" {
+		return s
+	}
+	if emailPattern.MatchString(s) {
+		return scrubEmails(s)
+	}
+	return "name-" + shortHash(s)
+}
+
+func shortHash(s string) string {
+	hash := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(hash[:4])
+}
+
+func isDroppedHeader(name string) bool {
+	for _, header := range cassetteDroppedHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return false
+}
@@ -52,12 +52,33 @@ func getOktaDomainNameUncached(ctx context.Context, d *plugin.QueryData, h *plug
 		domain = &envDomain
 	}
 
-	// Extract the domain name by removing the "https://" prefix
-	splitDomain := strings.SplitN(*domain, "https://", 2)
-	if len(splitDomain) != 2 {
+	// The domain may still be empty if the connection relies on an okta.yaml file,
+	// in which case use the org URL resolved by the SDK
+	if *domain == "" {
+		client, err := Connect(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		orgUrl := client.GetConfig().Okta.Client.OrgUrl
+		domain = &orgUrl
+	}
+
+	domainName := normalizeOktaDomain(*domain)
+	if domainName == "" {
 		return nil, fmt.Errorf("invalid okta domain format: %s", *domain)
 	}
-	domainName := splitDomain[1]
 
 	return domainName, nil
 }
+
+// normalizeOktaDomain extracts the host name from an Okta org URL, e.g.
+// "https://Example.okta.com/" becomes "example.okta.com", so that
+// `where domain = '...'` matches regardless of how the connection was configured.
+func normalizeOktaDomain(domain string) string {
+	domainName := strings.TrimSpace(domain)
+	domainName = strings.TrimPrefix(domainName, "https://")
+	domainName = strings.TrimPrefix(domainName, "http://")
+	domainName = strings.TrimRight(domainName, "/")
+
+	return strings.ToLower(domainName)
+}
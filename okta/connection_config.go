@@ -12,6 +12,19 @@ type oktaConfig struct {
 	RequestTimeout *int64  `hcl:"request_timeout"`
 	MaxRetries     *int32  `hcl:"max_retries"`
 	MaxBackoff     *int64  `hcl:"max_backoff"`
+
+	RateLimitPercent       *int64    `hcl:"rate_limit_percent"`
+	IgnoreConnectionErrors *bool     `hcl:"ignore_connection_errors"`
+	IgnoreErrorCodes       *[]string `hcl:"ignore_error_codes"`
+
+	UserGroupsIndexThreshold *int64 `hcl:"user_groups_index_threshold"`
+	ListCacheTtl             *int64 `hcl:"list_cache_ttl"`
+	UserFanoutConcurrency    *int64 `hcl:"user_fanout_concurrency"`
+
+	IncrementalSyncStateDir *string `hcl:"incremental_sync_state_dir"`
+
+	CassetteMode *string `hcl:"cassette_mode"`
+	CassetteDir  *string `hcl:"cassette_dir"`
 }
 
 func ConfigInstance() interface{} {
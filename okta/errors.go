@@ -0,0 +1,302 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	oktaV4 "github.com/okta/okta-sdk-golang/v4/okta"
+	oktaV5 "github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// HTTP status of the Okta error codes handled by the plugin. The v2 SDK only
+// returns the error code, so the status is inferred from it when the response
+// isn't available.
+// https://developer.okta.com/docs/reference/error-codes/
+var errorCodeStatus = map[string]int{
+	"E0000001": 400, // API validation failed
+	"E0000004": 401, // Authentication exception
+	"E0000005": 403, // Invalid session
+	"E0000006": 403, // You do not have permission to perform the requested action
+	"E0000007": 404, // Not found: Resource not found
+	"E0000009": 500, // Internal Server Error
+	"E0000011": 401, // Invalid token provided
+	"E0000015": 403, // You do not have permission to access the feature you are requesting
+	"E0000047": 429, // API call exceeded rate limit due to too many requests
+}
+
+// oktaError is an error returned by the Okta API, normalized across the v2, v4
+// and v5 SDKs.
+type oktaError struct {
+	StatusCode   int
+	ErrorCode    string
+	ErrorSummary string
+	err          error
+}
+
+func (e *oktaError) Error() string {
+	return e.err.Error()
+}
+
+func (e *oktaError) Unwrap() error {
+	return e.err
+}
+
+// responseStatusError attaches the HTTP status of the response to an error of
+// the v2 SDK, which is returned separately from the error.
+type responseStatusError struct {
+	statusCode int
+	err        error
+}
+
+func (e *responseStatusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseStatusError) Unwrap() error {
+	return e.err
+}
+
+// withResponseStatus attaches the HTTP status of a v2 SDK response to the error
+// returned with it, so that errors with unmapped or no error codes, e.g. Bearer
+// 401s, can be classified by status.
+func withResponseStatus(err error, resp *okta.Response) error {
+	if err == nil || resp == nil || resp.Response == nil {
+		return err
+	}
+	return &responseStatusError{statusCode: resp.StatusCode, err: err}
+}
+
+// parseOktaError returns the HTTP status and Okta error code of an error
+// returned by any of the SDKs, or nil if err is not an Okta API error.
+func parseOktaError(err error) *oktaError {
+	if err == nil {
+		return nil
+	}
+
+	var typedErr *oktaError
+	if errors.As(err, &typedErr) {
+		return typedErr
+	}
+
+	// v2 SDK
+	var v2Err *okta.Error
+	if errors.As(err, &v2Err) {
+		statusCode := errorCodeStatus[v2Err.ErrorCode]
+		var statusErr *responseStatusError
+		if errors.As(err, &statusErr) {
+			statusCode = statusErr.statusCode
+		}
+		return &oktaError{
+			StatusCode:   statusCode,
+			ErrorCode:    v2Err.ErrorCode,
+			ErrorSummary: v2Err.ErrorSummary,
+			err:          err,
+		}
+	}
+
+	// The v4 and v5 SDKs return the HTTP status as the error message, e.g.
+	// "404 Not Found", and the Okta error in the response body
+	var body []byte
+	var v4Err *oktaV4.GenericOpenAPIError
+	var v5Err *oktaV5.GenericOpenAPIError
+	switch {
+	case errors.As(err, &v4Err):
+		body = v4Err.Body()
+	case errors.As(err, &v5Err):
+		body = v5Err.Body()
+	default:
+		return nil
+	}
+
+	e := &oktaError{err: err}
+	if status, _, ok := strings.Cut(err.Error(), " "); ok {
+		e.StatusCode, _ = strconv.Atoi(status)
+	}
+	var apiErr struct {
+		ErrorCode    string `json:"errorCode"`
+		ErrorSummary string `json:"errorSummary"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil {
+		e.ErrorCode = apiErr.ErrorCode
+		e.ErrorSummary = apiErr.ErrorSummary
+	}
+	if e.StatusCode == 0 {
+		e.StatusCode = errorCodeStatus[e.ErrorCode]
+	}
+
+	return e
+}
+
+// isNotFoundError returns true if the requested resource does not exist.
+func isNotFoundError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && (e.StatusCode == 404 || e.ErrorCode == "E0000007")
+}
+
+// isForbiddenError returns true if the token or service app isn't allowed to
+// perform the request.
+func isForbiddenError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && e.StatusCode == 403
+}
+
+// isRateLimitError returns true if the request exceeded the rate limit.
+func isRateLimitError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && (e.StatusCode == 429 || e.ErrorCode == "E0000047")
+}
+
+// isConnectionError returns true if the error indicates the org could not be
+// reached or the credentials were rejected.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if e := parseOktaError(err); e != nil {
+		return e.StatusCode == 401
+	}
+
+	// Errors raised by the SDKs before a request is made
+	message := err.Error()
+	for _, item := range []string{"invalid_client", "invalid private key", "network error", "error in retrieving config"} {
+		if strings.Contains(message, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleOktaError adds the Okta error code and summary to the error message,
+// which the v4 and v5 SDKs omit, and hints at missing OAuth scopes when a
+// service app is denied access.
+func handleOktaError(d *plugin.QueryData, err error) error {
+	// The error has already been handled, e.g. by a paginator
+	var handled *oktaError
+	if errors.As(err, &handled) {
+		return err
+	}
+
+	e := parseOktaError(err)
+	if e == nil {
+		return err
+	}
+
+	details := ""
+	if e.ErrorCode != "" {
+		if strings.Contains(err.Error(), e.ErrorSummary) {
+			details = fmt.Sprintf(" (%s)", e.ErrorCode)
+		} else {
+			details = fmt.Sprintf(" (%s: %s)", e.ErrorCode, e.ErrorSummary)
+		}
+	}
+	if isForbiddenError(e) && usesServiceApp(d) {
+		details = details + ". The service app may not have been granted the OAuth scope required by this table, check the granted_scopes column of the okta_connection_info table"
+	}
+
+	return &oktaError{
+		StatusCode:   e.StatusCode,
+		ErrorCode:    e.ErrorCode,
+		ErrorSummary: e.ErrorSummary,
+		err:          fmt.Errorf("%w%s", err, details),
+	}
+}
+
+// usesServiceApp returns true if the connection authenticates with a service
+// app (PrivateKey auth mode) rather than an API token.
+func usesServiceApp(d *plugin.QueryData) bool {
+	_, token, clientID, privateKey, _, _, _, err := getOktaConfigValues(d)
+	return err == nil && token == "" && clientID != "" && privateKey != ""
+}
+
+// shouldIgnoreErrors returns an error predicate which ignores the errors matched
+// by any of the given predicates, in addition to the errors ignored by
+// shouldIgnoreErrorPluginDefault.
+func shouldIgnoreErrors(predicates ...plugin.ErrorPredicate) plugin.ErrorPredicateWithContext {
+	pluginDefault := shouldIgnoreErrorPluginDefault()
+	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
+		for _, predicate := range predicates {
+			if predicate(err) {
+				return true
+			}
+		}
+		return pluginDefault(ctx, d, h, err)
+	}
+}
+
+// shouldIgnoreErrorPluginDefault is applied to every hydrate call that does not
+// define its own ignore config.
+//
+// When a connection sets ignore_connection_errors, authentication and network
+// failures for that org return no rows instead of failing the query, so a single
+// misconfigured org doesn't break queries against an aggregator connection.
+//
+// Errors matching ignore_error_codes are ignored as well, so tokens that can't
+// read some endpoints return NULL for the columns hydrated from them.
+func shouldIgnoreErrorPluginDefault() plugin.ErrorPredicateWithContext {
+	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
+		config := GetConfig(d.Connection)
+
+		if config.IgnoreConnectionErrors != nil && *config.IgnoreConnectionErrors && isConnectionError(err) {
+			plugin.Logger(ctx).Warn("shouldIgnoreErrorPluginDefault", "connection", d.Connection.Name, "ignored_connection_error", err)
+			return true
+		}
+
+		if config.IgnoreErrorCodes != nil && matchesErrorCodes(err, *config.IgnoreErrorCodes) {
+			plugin.Logger(ctx).Warn("shouldIgnoreErrorPluginDefault", "connection", d.Connection.Name, "ignored_error_code", err)
+			return true
+		}
+
+		return false
+	}
+}
+
+// matchesErrorCodes returns true if the Okta error code (e.g. E0000006) or the
+// HTTP status (e.g. 403) of err is one of codes.
+func matchesErrorCodes(err error, codes []string) bool {
+	if len(codes) == 0 {
+		return false
+	}
+
+	e := parseOktaError(err)
+	if e == nil {
+		return false
+	}
+
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if e.ErrorCode != "" && strings.EqualFold(code, e.ErrorCode) {
+			return true
+		}
+		if e.StatusCode != 0 && code == strconv.Itoa(e.StatusCode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldRetryErrorPluginDefault retries requests that were rate limited or
+// failed on the server side after the SDK exhausted its own retries.
+func shouldRetryErrorPluginDefault() plugin.ErrorPredicateWithContext {
+	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
+		if isRateLimitError(err) {
+			return true
+		}
+		e := parseOktaError(err)
+		return e != nil && e.StatusCode >= 500
+	}
+}
@@ -0,0 +1,204 @@
+package okta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Directory of the JSON fixtures served by the fake Okta server. Each request
+// path is served from <fixtureDir><path>.json, e.g. GET /api/v1/users/00u1 is
+// served from testdata/fake_okta/api/v1/users/00u1.json.
+//
+// A fixture can be specialised for a query parameter by suffixing the file
+// name with @<name>=<value>, e.g. api/v1/policies@type=PASSWORD.json.
+const fixtureDir = "testdata/fake_okta"
+
+// fakeOktaServer is a local Okta API serving the fixtures over TLS, so that the
+// SDK clients can be pointed at it without disabling their HTTPS checks.
+type fakeOktaServer struct {
+	*httptest.Server
+
+	// Maximum number of items per page of list responses, lower than the
+	// maximum page size of the endpoints so that pagination is exercised
+	pageSize int
+
+	mutex       sync.Mutex
+	requests    []string
+	rateLimited map[string]int
+	failures    map[string]fakeOktaFailure
+}
+
+// fakeOktaFailure is an Okta error returned for every request to a path.
+type fakeOktaFailure struct {
+	status int
+	code   string
+}
+
+func newFakeOktaServer(t *testing.T) *fakeOktaServer {
+	t.Helper()
+
+	s := &fakeOktaServer{
+		pageSize:    2,
+		rateLimited: map[string]int{},
+		failures:    map[string]fakeOktaFailure{},
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// rateLimit makes the next n requests to the path fail with a 429 response.
+func (s *fakeOktaServer) rateLimit(path string, n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rateLimited[path] = n
+}
+
+// fail makes every request to the path fail with the status and Okta error
+// code, e.g. 403 E0000015 for the features an org doesn't have.
+func (s *fakeOktaServer) fail(path string, status int, code string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures[path] = fakeOktaFailure{status: status, code: code}
+}
+
+// Requests returns the method and URL of every request served, in order.
+func (s *fakeOktaServer) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// countRequests returns the number of requests served whose method and URL
+// start with prefix, e.g. "GET /api/v1/users?".
+func (s *fakeOktaServer) countRequests(prefix string) int {
+	n := 0
+	for _, request := range s.Requests() {
+		if strings.HasPrefix(request, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *fakeOktaServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	rateLimited := s.rateLimited[r.URL.Path] > 0
+	if rateLimited {
+		s.rateLimited[r.URL.Path]--
+	}
+	failure, failed := s.failures[r.URL.Path]
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if rateLimited {
+		// The SDKs wait until X-Rate-Limit-Reset, relative to the Date header
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("X-Rate-Limit-Limit", "600")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(now.Unix(), 10))
+		writeOktaError(w, http.StatusTooManyRequests, "E0000047", "API call exceeded rate limit due to too many requests.")
+		return
+	}
+
+	if failed {
+		writeOktaError(w, failure.status, failure.code, http.StatusText(failure.status))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeOktaError(w, http.StatusMethodNotAllowed, "E0000022", "The endpoint does not support the provided HTTP method")
+		return
+	}
+
+	data, err := readFixture(r.URL)
+	if os.IsNotExist(err) {
+		writeOktaError(w, http.StatusNotFound, "E0000007", "Not found: Resource not found: "+r.URL.Path)
+		return
+	}
+	if err != nil {
+		writeOktaError(w, http.StatusInternalServerError, "E0000009", err.Error())
+		return
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(data, &items) != nil {
+		_, _ = w.Write(data)
+		return
+	}
+	s.writePage(w, r, items)
+}
+
+// writePage writes the page of items starting at the after cursor, with a Link
+// header to the next page if there is one.
+func (s *fakeOktaServer) writePage(w http.ResponseWriter, r *http.Request, items []json.RawMessage) {
+	query := r.URL.Query()
+
+	limit := s.pageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l < limit {
+		limit = l
+	}
+	start, _ := strconv.Atoi(query.Get("after"))
+	start = min(start, len(items))
+	end := min(start+limit, len(items))
+
+	links := []string{fmt.Sprintf("<https://%s%s>; rel=\"self\"", r.Host, r.URL.RequestURI())}
+	if end < len(items) {
+		query.Set("after", strconv.Itoa(end))
+		links = append(links, fmt.Sprintf("<https://%s%s?%s>; rel=\"next\"", r.Host, r.URL.Path, query.Encode()))
+	}
+	for _, link := range links {
+		w.Header().Add("Link", link)
+	}
+
+	_ = json.NewEncoder(w).Encode(items[start:end])
+}
+
+// readFixture reads the fixture of the request URL, preferring fixtures
+// specialised for one of its query parameters.
+func readFixture(u *url.URL) ([]byte, error) {
+	path := filepath.Join(fixtureDir, filepath.FromSlash(strings.TrimSuffix(u.Path, "/")))
+
+	query := u.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if name != "limit" && name != "after" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(fmt.Sprintf("%s@%s=%s.json", path, name, query.Get(name)))
+		if err == nil || !os.IsNotExist(err) {
+			return data, err
+		}
+	}
+	return os.ReadFile(path + ".json")
+}
+
+func writeOktaError(w http.ResponseWriter, status int, code string, summary string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errorCode":    code,
+		"errorSummary": summary,
+		"errorLink":    code,
+		"errorId":      "oaeFakeOktaServer",
+		"errorCauses":  []interface{}{},
+	})
+}
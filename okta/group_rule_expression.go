@@ -0,0 +1,821 @@
+package okta
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+)
+
+// Node types of a parsed Okta Expression Language expression
+const (
+	expressionLiteral     = "literal"
+	expressionAttribute   = "attribute"
+	expressionCall        = "call"
+	expressionBinary      = "binary"
+	expressionUnary       = "unary"
+	expressionList        = "list"
+	expressionConditional = "conditional"
+)
+
+// Functions of group rule expressions referencing groups by ID, and by name
+var (
+	groupIdFunctions   = []string{"isMemberOfGroup", "isMemberOfAnyGroup"}
+	groupNameFunctions = []string{"isMemberOfGroupName", "isMemberOfGroupNameStartsWith", "isMemberOfGroupNameContains", "isMemberOfGroupNameRegex"}
+)
+
+// ExpressionNode is a node of the syntax tree of an Okta Expression Language
+// expression, e.g. user.department == "Engineering" is parsed into a binary
+// node with the == operator, an attribute node and a literal node.
+type ExpressionNode struct {
+	Type string `json:"type"`
+	// Value of a literal, i.e. a string, a float64, a bool or nil
+	Value interface{} `json:"value,omitempty"`
+	// Dotted path of an attribute, e.g. user.department
+	Attribute string `json:"attribute,omitempty"`
+	// Name of a function, e.g. String.startsWith, or of a method called on
+	// the receiver, e.g. substring
+	Function string          `json:"function,omitempty"`
+	Receiver *ExpressionNode `json:"receiver,omitempty"`
+	// Operator of a binary or unary node, e.g. ==, && or !
+	Operator string `json:"operator,omitempty"`
+	// Operands of an operator or a conditional, arguments of a call, or items
+	// of a list
+	Args []*ExpressionNode `json:"args,omitempty"`
+}
+
+// walk calls fn for the node and every node below it.
+func (n *ExpressionNode) walk(fn func(node *ExpressionNode)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	n.Receiver.walk(fn)
+	for _, arg := range n.Args {
+		arg.walk(fn)
+	}
+}
+
+// attributes returns the distinct attributes referenced by the expression,
+// e.g. user.department, in order of appearance.
+func (n *ExpressionNode) attributes() []string {
+	attributes := []string{}
+	n.walk(func(node *ExpressionNode) {
+		if node.Type == expressionAttribute && !slices.Contains(attributes, node.Attribute) {
+			attributes = append(attributes, node.Attribute)
+		}
+	})
+	return attributes
+}
+
+// functionStringArgs returns the distinct string literals passed to the
+// functions, e.g. the group IDs of isMemberOfAnyGroup("00g1", "00g2").
+func (n *ExpressionNode) functionStringArgs(functions []string) []string {
+	values := []string{}
+	n.walk(func(node *ExpressionNode) {
+		if node.Type != expressionCall || node.Receiver != nil || !slices.Contains(functions, node.Function) {
+			return
+		}
+		for _, arg := range node.Args {
+			arg.walk(func(item *ExpressionNode) {
+				if s, ok := item.Value.(string); ok && item.Type == expressionLiteral && !slices.Contains(values, s) {
+					values = append(values, s)
+				}
+			})
+		}
+	})
+	return values
+}
+
+//// PARSER
+
+// parseExpression parses an Okta Expression Language expression, as used by
+// group rules, into a syntax tree.
+func parseExpression(expression string) (*ExpressionNode, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens}
+	node, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", token.text, token.pos)
+	}
+	return node, nil
+}
+
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type expressionToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// Operators, longest first so that e.g. == isn't read as =
+var expressionOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "{", "}", ",", ".", "?", ":", "+", "-", "*", "/", "%"}
+
+func tokenizeExpression(expression string) ([]expressionToken, error) {
+	var tokens []expressionToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			start := i
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					value.WriteRune(runes[i])
+					continue
+				}
+				// SpEL escapes a quote by doubling it
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						value.WriteRune(r)
+						continue
+					}
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, expressionToken{kind: tokenString, text: value.String(), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || (runes[i] == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]))) {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			matched := false
+			for _, operator := range expressionOperators {
+				if strings.HasPrefix(string(runes[i:]), operator) {
+					tokens = append(tokens, expressionToken{kind: tokenOperator, text: operator, pos: i})
+					i += len([]rune(operator))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+			}
+		}
+	}
+	return append(tokens, expressionToken{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// expressionParser is a recursive descent parser, one method per precedence
+// level from the lowest to the highest.
+type expressionParser struct {
+	tokens []expressionToken
+	pos    int
+}
+
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() expressionToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// acceptOperator consumes the next token if it is one of the operators, or
+// one of the keywords, e.g. AND, and returns the normalized operator.
+func (p *expressionParser) acceptOperator(operators map[string]string) (string, bool) {
+	token := p.peek()
+	text := token.text
+	if token.kind == tokenIdent {
+		text = strings.ToLower(text)
+	} else if token.kind != tokenOperator {
+		return "", false
+	}
+	if operator, ok := operators[text]; ok {
+		p.next()
+		return operator, true
+	}
+	return "", false
+}
+
+func (p *expressionParser) expect(operator string) error {
+	if token := p.next(); token.kind != tokenOperator || token.text != operator {
+		if token.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", operator)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", operator, token.pos, token.text)
+	}
+	return nil
+}
+
+func (p *expressionParser) parseConditional() (*ExpressionNode, error) {
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOperator(map[string]string{"?": "?"}); !ok {
+		return condition, nil
+	}
+	ifTrue, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	ifFalse, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &ExpressionNode{Type: expressionConditional, Args: []*ExpressionNode{condition, ifTrue, ifFalse}}, nil
+}
+
+// Binary operators by precedence, from the lowest to the highest, including
+// the SpEL keywords
+var expressionBinaryOperators = []map[string]string{
+	{"||": "||", "or": "||"},
+	{"&&": "&&", "and": "&&"},
+	{"==": "==", "!=": "!=", "eq": "==", "ne": "!="},
+	{"<": "<", ">": ">", "<=": "<=", ">=": ">=", "lt": "<", "gt": ">", "le": "<=", "ge": ">="},
+	{"+": "+", "-": "-"},
+	{"*": "*", "/": "/", "%": "%"},
+}
+
+func (p *expressionParser) parseBinary(level int) (*ExpressionNode, error) {
+	if level == len(expressionBinaryOperators) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.acceptOperator(expressionBinaryOperators[level])
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &ExpressionNode{Type: expressionBinary, Operator: operator, Args: []*ExpressionNode{left, right}}
+	}
+}
+
+func (p *expressionParser) parseUnary() (*ExpressionNode, error) {
+	if operator, ok := p.acceptOperator(map[string]string{"!": "!", "not": "!", "-": "-"}); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionNode{Type: expressionUnary, Operator: operator, Args: []*ExpressionNode{operand}}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses attribute paths, e.g. user.department, static function
+// calls, e.g. String.startsWith(user.login, "a"), and method calls, e.g.
+// user.login.substring(0, 3).
+func (p *expressionParser) parsePostfix() (*ExpressionNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOperator(map[string]string{".": "."}); !ok {
+			return node, nil
+		}
+		token := p.next()
+		if token.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a name at position %d", token.pos)
+		}
+		if p.peek().kind != tokenOperator || p.peek().text != "(" {
+			if node.Type != expressionAttribute {
+				return nil, fmt.Errorf("unexpected property %q at position %d", token.text, token.pos)
+			}
+			node.Attribute += "." + token.text
+			continue
+		}
+
+		args, err := p.parseArgs("(", ")")
+		if err != nil {
+			return nil, err
+		}
+		// Functions are namespaced by capitalized names, e.g. String and Arrays
+		if node.Type == expressionAttribute && !strings.Contains(node.Attribute, ".") && unicode.IsUpper([]rune(node.Attribute)[0]) {
+			node = &ExpressionNode{Type: expressionCall, Function: node.Attribute + "." + token.text, Args: args}
+		} else {
+			node = &ExpressionNode{Type: expressionCall, Function: token.text, Receiver: node, Args: args}
+		}
+	}
+}
+
+func (p *expressionParser) parsePrimary() (*ExpressionNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case tokenString:
+		p.next()
+		return &ExpressionNode{Type: expressionLiteral, Value: token.text}, nil
+	case tokenNumber:
+		p.next()
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", token.text, token.pos)
+		}
+		return &ExpressionNode{Type: expressionLiteral, Value: value}, nil
+	case tokenIdent:
+		p.next()
+		switch strings.ToLower(token.text) {
+		case "true":
+			return &ExpressionNode{Type: expressionLiteral, Value: true}, nil
+		case "false":
+			return &ExpressionNode{Type: expressionLiteral, Value: false}, nil
+		case "null":
+			return &ExpressionNode{Type: expressionLiteral}, nil
+		}
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			args, err := p.parseArgs("(", ")")
+			if err != nil {
+				return nil, err
+			}
+			return &ExpressionNode{Type: expressionCall, Function: token.text, Args: args}, nil
+		}
+		return &ExpressionNode{Type: expressionAttribute, Attribute: token.text}, nil
+	case tokenOperator:
+		switch token.text {
+		case "(":
+			p.next()
+			node, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "{":
+			items, err := p.parseArgs("{", "}")
+			if err != nil {
+				return nil, err
+			}
+			return &ExpressionNode{Type: expressionList, Args: items}, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", token.text, token.pos)
+}
+
+// parseArgs parses a comma separated list of expressions between the open
+// and close operators.
+func (p *expressionParser) parseArgs(open, close string) ([]*ExpressionNode, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	args := []*ExpressionNode{}
+	if next := p.peek(); next.kind == tokenOperator && next.text == close {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if _, ok := p.acceptOperator(map[string]string{",": ","}); !ok {
+			return args, p.expect(close)
+		}
+	}
+}
+
+//// EVALUATOR
+
+// expressionEnv is what an expression is evaluated against: a user, and the
+// groups the user is a direct member of.
+type expressionEnv struct {
+	profile map[string]interface{}
+	groups  []*okta.Group
+}
+
+// evaluateGroupRuleExpression returns whether the user matches the
+// expression. Errors are returned for expressions that use functions or
+// attributes that can't be evaluated offline.
+func evaluateGroupRuleExpression(node *ExpressionNode, env *expressionEnv) (bool, error) {
+	value, err := node.evaluate(env)
+	if err != nil {
+		return false, err
+	}
+	matches, ok := value.(bool)
+	if !ok && value != nil {
+		return false, fmt.Errorf("expression returned %T, expected a boolean", value)
+	}
+	return matches, nil
+}
+
+func (n *ExpressionNode) evaluate(env *expressionEnv) (interface{}, error) {
+	switch n.Type {
+	case expressionLiteral:
+		return n.Value, nil
+
+	case expressionAttribute:
+		attribute, isUser := strings.CutPrefix(n.Attribute, "user.")
+		if !isUser || strings.Contains(attribute, ".") {
+			return nil, fmt.Errorf("unsupported attribute %s", n.Attribute)
+		}
+		return env.profile[attribute], nil
+
+	case expressionList:
+		return n.evaluateArgs(env)
+
+	case expressionConditional:
+		condition, err := n.Args[0].evaluateBool(env)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			return n.Args[1].evaluate(env)
+		}
+		return n.Args[2].evaluate(env)
+
+	case expressionUnary:
+		if n.Operator == "!" {
+			operand, err := n.Args[0].evaluateBool(env)
+			return !operand, err
+		}
+		operand, err := n.Args[0].evaluate(env)
+		if err != nil {
+			return nil, err
+		}
+		number, ok := operand.(float64)
+		if !ok {
+			return nil, fmt.Errorf("can't negate %T", operand)
+		}
+		return -number, nil
+
+	case expressionBinary:
+		return n.evaluateBinary(env)
+
+	case expressionCall:
+		if n.Receiver != nil {
+			return n.evaluateMethod(env)
+		}
+		return n.evaluateFunction(env)
+	}
+	return nil, fmt.Errorf("unsupported expression %s", n.Type)
+}
+
+// evaluateBool evaluates a condition, where null is false.
+func (n *ExpressionNode) evaluateBool(env *expressionEnv) (bool, error) {
+	value, err := n.evaluate(env)
+	if err != nil || value == nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %T", value)
+	}
+	return b, nil
+}
+
+func (n *ExpressionNode) evaluateArgs(env *expressionEnv) ([]interface{}, error) {
+	values := make([]interface{}, len(n.Args))
+	for i, arg := range n.Args {
+		value, err := arg.evaluate(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (n *ExpressionNode) evaluateBinary(env *expressionEnv) (interface{}, error) {
+	// Logical operators short-circuit
+	switch n.Operator {
+	case "&&", "||":
+		left, err := n.Args[0].evaluateBool(env)
+		if err != nil {
+			return nil, err
+		}
+		if left == (n.Operator == "||") {
+			return left, nil
+		}
+		return n.Args[1].evaluateBool(env)
+	}
+
+	args, err := n.evaluateArgs(env)
+	if err != nil {
+		return nil, err
+	}
+	left, right := args[0], args[1]
+
+	switch n.Operator {
+	case "==":
+		return expressionValuesEqual(left, right), nil
+	case "!=":
+		return !expressionValuesEqual(left, right), nil
+	case "+":
+		if l, ok := left.(string); ok {
+			return l + expressionString(right), nil
+		}
+		if r, ok := right.(string); ok {
+			return expressionString(left) + r, nil
+		}
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch n.Operator {
+		case "<":
+			return l < r, nil
+		case ">":
+			return l > r, nil
+		case "<=":
+			return l <= r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			return l / r, nil
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch n.Operator {
+		case "<":
+			return l < r, nil
+		case ">":
+			return l > r, nil
+		case "<=":
+			return l <= r, nil
+		case ">=":
+			return l >= r, nil
+		}
+	}
+	// Comparisons with null, e.g. of a missing attribute, are false
+	if left == nil || right == nil {
+		return false, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %T %s %T", left, n.Operator, right)
+}
+
+// evaluateFunction evaluates the functions of the Okta Expression Language
+// most used by group rules.
+func (n *ExpressionNode) evaluateFunction(env *expressionEnv) (interface{}, error) {
+	args, err := n.evaluateArgs(env)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = expressionString(arg)
+	}
+	argCount := func(counts ...int) error {
+		if !slices.Contains(counts, len(args)) {
+			return fmt.Errorf("%s expects %v arguments, got %d", n.Function, counts, len(args))
+		}
+		return nil
+	}
+
+	switch n.Function {
+	case "String.startsWith":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		return args[0] != nil && strings.HasPrefix(strs[0], strs[1]), nil
+	case "String.endsWith":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		return args[0] != nil && strings.HasSuffix(strs[0], strs[1]), nil
+	case "String.stringContains":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		return args[0] != nil && strings.Contains(strs[0], strs[1]), nil
+	case "String.toLowerCase":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return strings.ToLower(strs[0]), nil
+	case "String.toUpperCase":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return strings.ToUpper(strs[0]), nil
+	case "String.len":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return float64(len([]rune(strs[0]))), nil
+	case "String.removeSpaces":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(strs[0], " ", ""), nil
+	case "String.substringBefore":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		before, _, _ := strings.Cut(strs[0], strs[1])
+		return before, nil
+	case "String.substringAfter":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		_, after, found := strings.Cut(strs[0], strs[1])
+		if !found {
+			return "", nil
+		}
+		return after, nil
+
+	case "Arrays.contains":
+		if err := argCount(2); err != nil {
+			return nil, err
+		}
+		for _, item := range expressionItems(args[0]) {
+			if expressionValuesEqual(item, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "Arrays.isEmpty":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return len(expressionItems(args[0])) == 0, nil
+	case "Arrays.size":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		return float64(len(expressionItems(args[0]))), nil
+
+	case "isMemberOfGroup", "isMemberOfAnyGroup":
+		ids := []string{}
+		for _, arg := range args {
+			for _, item := range expressionItems(arg) {
+				ids = append(ids, expressionString(item))
+			}
+		}
+		return slices.ContainsFunc(env.groups, func(group *okta.Group) bool {
+			return slices.Contains(ids, group.Id)
+		}), nil
+	case "isMemberOfGroupName", "isMemberOfGroupNameStartsWith", "isMemberOfGroupNameContains", "isMemberOfGroupNameRegex":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		var pattern *regexp.Regexp
+		if n.Function == "isMemberOfGroupNameRegex" {
+			// Okta matches the whole group name
+			pattern, err = regexp.Compile("^(?:" + strs[0] + ")$")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return slices.ContainsFunc(env.groups, func(group *okta.Group) bool {
+			if group.Profile == nil {
+				return false
+			}
+			name := group.Profile.Name
+			switch n.Function {
+			case "isMemberOfGroupName":
+				return name == strs[0]
+			case "isMemberOfGroupNameStartsWith":
+				return strings.HasPrefix(name, strs[0])
+			case "isMemberOfGroupNameContains":
+				return strings.Contains(name, strs[0])
+			default:
+				return pattern.MatchString(name)
+			}
+		}), nil
+	}
+	return nil, fmt.Errorf("unsupported function %s", n.Function)
+}
+
+// evaluateMethod evaluates the Java string methods that expressions may call
+// on attributes, e.g. user.login.toLowerCase().
+func (n *ExpressionNode) evaluateMethod(env *expressionEnv) (interface{}, error) {
+	receiver, err := n.Receiver.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := receiver.(string)
+	if !ok {
+		if receiver == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unsupported method %s of %T", n.Function, receiver)
+	}
+	args, err := n.evaluateArgs(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case n.Function == "toLowerCase" && len(args) == 0:
+		return strings.ToLower(s), nil
+	case n.Function == "toUpperCase" && len(args) == 0:
+		return strings.ToUpper(s), nil
+	case n.Function == "trim" && len(args) == 0:
+		return strings.TrimSpace(s), nil
+	case n.Function == "length" && len(args) == 0:
+		return float64(len([]rune(s))), nil
+	case n.Function == "startsWith" && len(args) == 1:
+		return strings.HasPrefix(s, expressionString(args[0])), nil
+	case n.Function == "endsWith" && len(args) == 1:
+		return strings.HasSuffix(s, expressionString(args[0])), nil
+	case n.Function == "contains" && len(args) == 1:
+		return strings.Contains(s, expressionString(args[0])), nil
+	case n.Function == "equals" && len(args) == 1:
+		return expressionValuesEqual(s, args[0]), nil
+	case n.Function == "equalsIgnoreCase" && len(args) == 1:
+		return strings.EqualFold(s, expressionString(args[0])), nil
+	}
+	return nil, fmt.Errorf("unsupported method %s with %d arguments", n.Function, len(args))
+}
+
+func expressionValuesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case float64:
+		b, ok := b.(float64)
+		return ok && a == b
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	}
+	return false
+}
+
+// expressionString converts a value to a string, e.g. for concatenation.
+func expressionString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// expressionItems returns the items of a list, or a single item list for any
+// other value but null.
+func expressionItems(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	}
+	return []interface{}{value}
+}
@@ -0,0 +1,206 @@
+package okta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	// Rows updated up to this long before the start of a sync may only be
+	// visible to the next sync, e.g. if the clocks of Okta and the plugin
+	// differ, so the next sync lists them again
+	syncSkewWindow = 5 * time.Minute
+
+	// A sync state is only committed this long after its sync completed, so
+	// that every scan of the table in the same query uses the same state
+	syncStateCommitDelay = time.Minute
+)
+
+// syncState is the high-water mark of the last complete incremental sync of a
+// table, i.e. the time the sync started.
+type syncState struct {
+	LastUpdated           *time.Time `json:"last_updated,omitempty"`
+	LastMembershipUpdated *time.Time `json:"last_membership_updated,omitempty"`
+	LastSynced            *time.Time `json:"last_synced,omitempty"`
+
+	// Versions of the rows synced within the skew window of the marks, which
+	// the next sync lists again but doesn't return
+	SyncedVersions map[string]string `json:"synced_versions,omitempty"`
+
+	// State of the last sync, until it is committed
+	Pending *syncState `json:"pending,omitempty"`
+}
+
+// syncListing builds the state of the next sync while listing the rows
+// changed since the previous one.
+type syncListing struct {
+	previous syncState
+	next     syncState
+}
+
+// Serializes reads and writes of the state files of every connection
+var syncStateLock sync.Mutex
+
+// isIncrementalSync returns true if the query requests an incremental sync,
+// i.e. has an incremental = true qual.
+func isIncrementalSync(d *plugin.QueryData) bool {
+	return d.EqualsQuals["incremental"] != nil && d.EqualsQuals["incremental"].GetBoolValue()
+}
+
+// syncStatePath returns the path of the file holding the sync state of every
+// table of the connection, in the directory set by the
+// incremental_sync_state_dir config argument.
+func syncStatePath(d *plugin.QueryData) (string, error) {
+	dir := ""
+	if config := GetConfig(d.Connection); config.IncrementalSyncStateDir != nil {
+		dir = *config.IncrementalSyncStateDir
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to find the home directory for the incremental sync state, set incremental_sync_state_dir instead: %v", err)
+		}
+		dir = filepath.Join(home, ".steampipe", "internal", "okta")
+	}
+	return filepath.Join(dir, d.Connection.Name+".json"), nil
+}
+
+func readSyncStates(path string) (map[string]syncState, error) {
+	states := map[string]syncState{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("invalid incremental sync state file %s: %v", path, err)
+	}
+	return states, nil
+}
+
+// loadSyncState returns the committed sync state of the queried table, which
+// is empty if the table has never been synced. The pending state of the last
+// sync is committed first if it completed more than syncStateCommitDelay ago.
+func loadSyncState(d *plugin.QueryData) (syncState, error) {
+	path, err := syncStatePath(d)
+	if err != nil {
+		return syncState{}, err
+	}
+
+	syncStateLock.Lock()
+	defer syncStateLock.Unlock()
+
+	states, err := readSyncStates(path)
+	if err != nil {
+		return syncState{}, err
+	}
+	state := states[d.Table.Name]
+	if pending := state.Pending; pending != nil && pending.LastSynced != nil && time.Since(*pending.LastSynced) >= syncStateCommitDelay {
+		state = *pending
+		states[d.Table.Name] = state
+		if err := writeSyncStates(path, states); err != nil {
+			return syncState{}, err
+		}
+	}
+	state.Pending = nil
+	return state, nil
+}
+
+// saveSyncState saves the state of a complete sync of the queried table as
+// pending, to be committed by a later sync. A pending state that isn't
+// committed yet is kept, since the sync was started from the same committed
+// state, e.g. by another scan of the same query.
+func saveSyncState(d *plugin.QueryData, state syncState) error {
+	path, err := syncStatePath(d)
+	if err != nil {
+		return err
+	}
+
+	syncStateLock.Lock()
+	defer syncStateLock.Unlock()
+
+	states, err := readSyncStates(path)
+	if err != nil {
+		return err
+	}
+	committed := states[d.Table.Name]
+	if committed.Pending != nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	state.LastSynced = &now
+	state.Pending = nil
+	committed.Pending = &state
+	states[d.Table.Name] = committed
+
+	return writeSyncStates(path, states)
+}
+
+// writeSyncStates replaces the state file atomically, so that an interrupted
+// write doesn't lose the state of the other tables.
+func writeSyncStates(path string, states map[string]syncState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// newSyncListing starts a sync from the previous state. The marks of the next
+// sync are the time the sync started rather than the latest timestamps
+// listed, since rows updated while listing may be listed in any order.
+func newSyncListing(previous syncState) *syncListing {
+	start := time.Now().UTC()
+	return &syncListing{
+		previous: previous,
+		next: syncState{
+			LastUpdated:           &start,
+			LastMembershipUpdated: &start,
+			SyncedVersions:        map[string]string{},
+		},
+	}
+}
+
+// syncSince returns the filter time of the rows changed since a mark of the
+// previous sync, including the rows of its skew window.
+func syncSince(mark *time.Time) string {
+	return mark.Add(-syncSkewWindow).UTC().Format(filterTimeFormat)
+}
+
+// seen returns true if the version of the row, i.e. its timestamps, was
+// already returned by the previous sync. Versions within the skew window of
+// the next sync are recorded for it.
+func (l *syncListing) seen(id string, timestamps ...*time.Time) bool {
+	version := ""
+	recent := false
+	for i, t := range timestamps {
+		if i > 0 {
+			version += "/"
+		}
+		if t != nil {
+			version += t.UTC().Format(time.RFC3339Nano)
+			recent = recent || !t.Before(l.next.LastUpdated.Add(-syncSkewWindow))
+		}
+	}
+	if recent {
+		l.next.SyncedVersions[id] = version
+	}
+	return version != "" && l.previous.SyncedVersions[id] == version
+}
@@ -0,0 +1,210 @@
+package okta
+
+import (
+	"context"
+	"sort"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	oktaV4 "github.com/okta/okta-sdk-golang/v4/okta"
+	oktaV5 "github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// paginator iterates over the pages of an Okta list endpoint, following the
+// Link header of each response. It hides the differences between the v2, v4
+// and v5 SDK responses, so that list functions only deal with items of type T.
+//
+// The first page is requested lazily, on the first call to ForEach, Stream or
+// All, and each page is requested only once the previous one was consumed.
+type paginator[T any] struct {
+	d    *plugin.QueryData
+	name string
+
+	// Requests the first page
+	first func() ([]T, error)
+	// Requests the page following the last one, if any
+	next    func(ctx context.Context) ([]T, error)
+	hasNext func() bool
+}
+
+// newPaginatorV2 returns a paginator over a list endpoint of the v2 SDK. name
+// identifies the list function in logs and in the okta_connection_info table.
+func newPaginatorV2[T any](d *plugin.QueryData, name string, list func() ([]T, *okta.Response, error)) *paginator[T] {
+	var resp *okta.Response
+	return &paginator[T]{
+		d:    d,
+		name: name,
+		first: func() ([]T, error) {
+			items, r, err := list()
+			resp = r
+			return items, withResponseStatus(err, r)
+		},
+		next: func(ctx context.Context) ([]T, error) {
+			var items []T
+			var err error
+			resp, err = resp.Next(ctx, &items)
+			return items, withResponseStatus(err, resp)
+		},
+		hasNext: func() bool {
+			return resp != nil && resp.HasNextPage()
+		},
+	}
+}
+
+// newPaginatorV4 returns a paginator over a list endpoint of the v4 SDK.
+func newPaginatorV4[T any](d *plugin.QueryData, name string, list func() ([]T, *oktaV4.APIResponse, error)) *paginator[T] {
+	var resp *oktaV4.APIResponse
+	return &paginator[T]{
+		d:    d,
+		name: name,
+		first: func() ([]T, error) {
+			items, r, err := list()
+			resp = r
+			return items, err
+		},
+		next: func(_ context.Context) ([]T, error) {
+			var items []T
+			var err error
+			resp, err = resp.Next(&items)
+			return items, err
+		},
+		hasNext: func() bool {
+			return resp != nil && resp.HasNextPage()
+		},
+	}
+}
+
+// newPaginatorV5 returns a paginator over a list endpoint of the v5 SDK.
+func newPaginatorV5[T any](d *plugin.QueryData, name string, list func() ([]T, *oktaV5.APIResponse, error)) *paginator[T] {
+	var resp *oktaV5.APIResponse
+	return &paginator[T]{
+		d:    d,
+		name: name,
+		first: func() ([]T, error) {
+			items, r, err := list()
+			resp = r
+			return items, err
+		},
+		next: func(_ context.Context) ([]T, error) {
+			var items []T
+			var err error
+			resp, err = resp.Next(&items)
+			return items, err
+		},
+		hasNext: func() bool {
+			return resp != nil && resp.HasNextPage()
+		},
+	}
+}
+
+// ForEach calls fn for every item of every page until fn returns false, the
+// context is cancelled or there are no more pages. API errors are returned
+// through handleOktaError, so callers can classify them with isNotFoundError
+// and friends.
+func (p *paginator[T]) ForEach(ctx context.Context, fn func(item T) bool) error {
+	logger := plugin.Logger(ctx)
+
+	var pages, items int64
+	defer func() {
+		getConnectionStats(p.d).recordPagination(p.name, pages, items)
+	}()
+
+	page, err := p.first()
+	for {
+		if err != nil {
+			logger.Error(p.name, "page", pages+1, "api_error", err)
+			return handleOktaError(p.d, err)
+		}
+		pages++
+
+		for _, item := range page {
+			items++
+			if !fn(item) {
+				return nil
+			}
+		}
+
+		if !p.hasNext() {
+			return nil
+		}
+
+		// Context can be cancelled due to manual cancellation while the
+		// previous page was being consumed
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		page, err = p.next(ctx)
+	}
+}
+
+// Stream streams every item to the query as a row, stopping as soon as the
+// limit of the query is reached.
+func (p *paginator[T]) Stream(ctx context.Context) error {
+	return p.StreamFunc(ctx, func(item T) interface{} {
+		return item
+	})
+}
+
+// StreamFunc streams the row built by row for every item, stopping as soon as
+// the limit of the query is reached. A nil row is skipped.
+func (p *paginator[T]) StreamFunc(ctx context.Context, row func(item T) interface{}) error {
+	return p.ForEach(ctx, func(item T) bool {
+		if r := row(item); r != nil {
+			p.d.StreamListItem(ctx, r)
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return p.d.RowsRemaining(ctx) != 0
+	})
+}
+
+// All returns the items of every page. It is meant for column hydrates, whose
+// value is the complete list.
+func (p *paginator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	err := p.ForEach(ctx, func(item T) bool {
+		all = append(all, item)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+type paginationInfo struct {
+	Name  string `json:"name"`
+	Calls int64  `json:"calls"`
+	Pages int64  `json:"pages"`
+	Items int64  `json:"items"`
+}
+
+func (s *connectionStats) recordPagination(name string, pages int64, items int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	info, ok := s.pagination[name]
+	if !ok {
+		info = &paginationInfo{Name: name}
+		s.pagination[name] = info
+	}
+	info.Calls++
+	info.Pages += pages
+	info.Items += items
+}
+
+// Pagination returns the number of calls, pages and items fetched per list function, sorted by name.
+func (s *connectionStats) Pagination() []paginationInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pagination := make([]paginationInfo, 0, len(s.pagination))
+	for _, info := range s.pagination {
+		pagination = append(pagination, *info)
+	}
+	sort.Slice(pagination, func(i, j int) bool {
+		return pagination[i].Name < pagination[j].Name
+	})
+	return pagination
+}
@@ -23,26 +23,40 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
 		},
+		DefaultIgnoreConfig: &plugin.IgnoreConfig{
+			ShouldIgnoreErrorFunc: shouldIgnoreErrorPluginDefault(),
+		},
+		DefaultRetryConfig: &plugin.RetryConfig{
+			ShouldRetryErrorFunc: shouldRetryErrorPluginDefault(),
+		},
 		TableMap: map[string]*plugin.Table{
-			"okta_app_assigned_group":    tableOktaApplicationAssignedGroup(),
-			"okta_app_assigned_user":     tableOktaApplicationAssignedUser(),
-			"okta_application":           tableOktaApplication(),
-			"okta_auth_server":           tableOktaAuthServer(),
-			"okta_authentication_policy": tableOktaAuthenticationPolicy(),
-			"okta_authenticator":         tableOktaAuthenticator(),
-			"okta_device":                tableOktaDevice(),
-			"okta_factor":                tableOktaFactor(),
-			"okta_group":                 tableOktaGroup(),
-			"okta_group_owner":           tableOktaGroupOwner(),
-			"okta_group_rule":            tableOktaGroupRule(),
-			"okta_idp_discovery_policy":  tableOktaIdpDiscoveryPolicy(),
-			"okta_mfa_policy":            tableOktaMfaPolicy(),
-			"okta_network_zone":          tableOktaNetworkZone(),
-			"okta_password_policy":       tableOktaPasswordPolicy(),
-			"okta_signon_policy":         tableOktaSignonPolicy(),
-			"okta_trusted_origin":        tableOktaTrustedOrigin(),
-			"okta_user":                  tableOktaUser(),
-			"okta_user_type":             tableOktaUserType(),
+			"okta_app_assigned_group":       tableOktaApplicationAssignedGroup(),
+			"okta_app_assigned_user":        tableOktaApplicationAssignedUser(),
+			"okta_application":              tableOktaApplication(),
+			"okta_auth_server":              tableOktaAuthServer(),
+			"okta_authentication_policy":    tableOktaAuthenticationPolicy(),
+			"okta_authenticator":            tableOktaAuthenticator(),
+			"okta_authenticator_enrollment": tableOktaAuthenticatorEnrollment(),
+			"okta_authenticator_method":     tableOktaAuthenticatorMethod(),
+			"okta_connection_info":          tableOktaConnectionInfo(),
+			"okta_device":                   tableOktaDevice(),
+			"okta_factor":                   tableOktaFactor(),
+			"okta_group":                    tableOktaGroup(),
+			"okta_group_owner":              tableOktaGroupOwner(),
+			"okta_group_rule":               tableOktaGroupRule(),
+			"okta_group_rule_match":         tableOktaGroupRuleMatch(),
+			"okta_idp_discovery_policy":     tableOktaIdpDiscoveryPolicy(),
+			"okta_mfa_policy":               tableOktaMfaPolicy(),
+			"okta_network_zone":             tableOktaNetworkZone(),
+			"okta_network_zone_address":     tableOktaNetworkZoneAddress(),
+			"okta_password_policy":          tableOktaPasswordPolicy(),
+			"okta_signon_policy":            tableOktaSignonPolicy(),
+			"okta_trusted_origin":           tableOktaTrustedOrigin(),
+			"okta_user":                     tableOktaUser(),
+			"okta_user_change_history":      tableOktaUserChangeHistory(),
+			"okta_user_linked_object":       tableOktaUserLinkedObject(),
+			"okta_user_mfa_posture":         tableOktaUserMfaPosture(),
+			"okta_user_type":                tableOktaUserType(),
 		},
 	}
 
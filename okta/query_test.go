@@ -0,0 +1,290 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	sdkgrpc "github.com/turbot/steampipe-plugin-sdk/v5/grpc"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the table tests")
+
+// Directory of the expected rows of each table test
+const goldenDir = "testdata/golden"
+
+// Org URL host of the connections to the fake Okta server
+const fakeOktaHost = "fake-okta.test"
+
+// testQuery is a scan of a table, as it would be requested by Postgres.
+type testQuery struct {
+	Table   string
+	Columns []string
+	Quals   map[string]interface{}
+	Limit   int64
+}
+
+// testConnection is a plugin instance with a single connection to a fake Okta
+// server, executing queries through the plugin SDK.
+type testConnection struct {
+	server       *fakeOktaServer
+	plugin       *plugin.Plugin
+	pluginServer *sdkgrpc.PluginServer
+	name         string
+}
+
+// newTestConnection starts a fake Okta server and a plugin instance with a
+// connection to it. The optional config is appended to the connection config.
+func newTestConnection(t *testing.T, config ...string) *testConnection {
+	t.Helper()
+
+	server := newFakeOktaServer(t)
+
+	// The SDK clients are given a clone of the default transport, so route
+	// every connection to the fake server there and trust its certificate.
+	// The v4 and v5 SDKs drop the port of the org URL, so the connection is
+	// configured with a fake host rather than the address of the server.
+	// The default transport is restored when the test ends.
+	transport := http.DefaultTransport.(*http.Transport)
+	dialContext, tlsClientConfig := transport.DialContext, transport.TLSClientConfig
+	t.Cleanup(func() {
+		transport.DialContext, transport.TLSClientConfig = dialContext, tlsClientConfig
+	})
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+
+	// Every test has its own connection so that no cache is shared between tests
+	name := "okta_" + regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(t.Name()), "_")
+
+	var p *plugin.Plugin
+	pluginServer := plugin.Server(&plugin.ServeOpts{PluginFunc: func(ctx context.Context) *plugin.Plugin {
+		p = Plugin(ctx)
+		return p
+	}})
+
+	connectionConfig := fmt.Sprintf("domain = \"https://%s\"\ntoken = \"fake-token\"\nmax_backoff = 1\nmax_retries = 2\n%s", fakeOktaHost, strings.Join(config, "\n"))
+	_, err := pluginServer.SetAllConnectionConfigs(&proto.SetAllConnectionConfigsRequest{
+		Configs: []*proto.ConnectionConfig{{
+			Connection:      name,
+			Plugin:          "hub.steampipe.io/plugins/turbot/okta@latest",
+			PluginShortName: "okta",
+			Config:          connectionConfig,
+		}},
+		MaxCacheSizeMb: -1,
+	})
+	if err != nil {
+		t.Fatalf("SetAllConnectionConfigs: %v", err)
+	}
+	// Rows must come from the fake server rather than the query cache
+	if _, err := pluginServer.SetCacheOptions(&proto.SetCacheOptionsRequest{Enabled: false}); err != nil {
+		t.Fatalf("SetCacheOptions: %v", err)
+	}
+
+	return &testConnection{server: server, plugin: p, pluginServer: pluginServer, name: name}
+}
+
+// query executes a scan of the table and returns its rows, without the
+// columns added by the SDK.
+func (c *testConnection) query(t *testing.T, q testQuery) ([]map[string]interface{}, error) {
+	t.Helper()
+
+	table, ok := c.plugin.TableMap[q.Table]
+	if !ok {
+		t.Fatalf("unknown table %s", q.Table)
+	}
+	columns := q.Columns
+	if len(columns) == 0 {
+		for _, column := range table.Columns {
+			columns = append(columns, column.Name)
+		}
+	}
+
+	queryContext := &proto.QueryContext{
+		Columns: columns,
+		Quals:   map[string]*proto.Quals{},
+	}
+	for column, value := range q.Quals {
+		queryContext.Quals[column] = &proto.Quals{Quals: []*proto.Qual{{
+			FieldName: column,
+			Operator:  &proto.Qual_StringValue{StringValue: "="},
+			Value:     qualValue(t, value),
+		}}}
+	}
+	var limit *proto.NullableInt
+	if q.Limit > 0 {
+		limit = &proto.NullableInt{Value: q.Limit}
+	}
+	queryContext.Limit = limit
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	stream := &testRowStream{ctx: ctx}
+	err := c.pluginServer.Execute(&proto.ExecuteRequest{
+		Table:        q.Table,
+		QueryContext: queryContext,
+		Connection:   c.name,
+		CallId:       fmt.Sprintf("%s-%d", c.name, time.Now().UnixNano()),
+		ExecuteConnectionData: map[string]*proto.ExecuteConnectionData{
+			c.name: {Limit: limit},
+		},
+	}, stream)
+
+	return stream.rows, err
+}
+
+// assertGolden compares the rows with the golden file of the test, or
+// rewrites the golden file when the tests are run with -update.
+func (c *testConnection) assertGolden(t *testing.T, rows []map[string]interface{}) {
+	t.Helper()
+
+	// Rows of a scan can be streamed in any order
+	encoded := make([]string, len(rows))
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			t.Fatalf("marshal row: %v", err)
+		}
+		encoded[i] = string(data)
+	}
+	sort.Strings(encoded)
+
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range encoded {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(row)
+	}
+	buf.WriteString("]")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		t.Fatalf("indent rows: %v", err)
+	}
+	indented.WriteString("\n")
+	actual := indented.String()
+
+	path := filepath.Join(goldenDir, strings.ReplaceAll(t.Name(), "/", "_")+".json")
+	if *update {
+		if err := os.MkdirAll(goldenDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file, run the tests with -update to create it: %v", err)
+	}
+	if actual != string(expected) {
+		t.Errorf("rows differ from %s, run the tests with -update to review the changes\nactual:\n%s", path, actual)
+	}
+}
+
+func qualValue(t *testing.T, value interface{}) *proto.QualValue {
+	switch v := value.(type) {
+	case string:
+		return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: v}}
+	case bool:
+		return &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: v}}
+	case int:
+		return &proto.QualValue{Value: &proto.QualValue_Int64Value{Int64Value: int64(v)}}
+	case time.Time:
+		return &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(v)}}
+	case netip.Addr:
+		prefix := netip.PrefixFrom(v, v.BitLen())
+		version := "IPv4"
+		if v.Is6() {
+			version = "IPv6"
+		}
+		return &proto.QualValue{Value: &proto.QualValue_InetValue{InetValue: &proto.Inet{Addr: v.String(), Mask: int32(prefix.Bits()), Cidr: prefix.String(), ProtocolVersion: version}}}
+	case []string:
+		values := make([]*proto.QualValue, len(v))
+		for i, s := range v {
+			values[i] = qualValue(t, s)
+		}
+		return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{Values: values}}}
+	}
+	t.Fatalf("unsupported qual value %T", value)
+	return nil
+}
+
+// testRowStream collects the rows streamed by an Execute call.
+type testRowStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	rows []map[string]interface{}
+}
+
+func (s *testRowStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testRowStream) Send(response *proto.ExecuteResponse) error {
+	if response == nil || response.Row == nil {
+		return nil
+	}
+
+	row := map[string]interface{}{}
+	for name, column := range response.Row.Columns {
+		// Columns added by the SDK
+		if name == "_ctx" || strings.HasPrefix(name, "sp_") {
+			continue
+		}
+		row[name] = columnValue(column)
+	}
+	s.rows = append(s.rows, row)
+	return nil
+}
+
+func columnValue(column *proto.Column) interface{} {
+	switch v := column.Value.(type) {
+	case *proto.Column_DoubleValue:
+		return v.DoubleValue
+	case *proto.Column_IntValue:
+		return v.IntValue
+	case *proto.Column_StringValue:
+		return v.StringValue
+	case *proto.Column_BoolValue:
+		return v.BoolValue
+	case *proto.Column_JsonValue:
+		var value interface{}
+		if err := json.Unmarshal(v.JsonValue, &value); err != nil {
+			return string(v.JsonValue)
+		}
+		return value
+	case *proto.Column_TimestampValue:
+		return v.TimestampValue.AsTime().UTC().Format(time.RFC3339)
+	case *proto.Column_IpAddrValue:
+		return v.IpAddrValue
+	case *proto.Column_CidrRangeValue:
+		return v.CidrRangeValue
+	case *proto.Column_LtreeValue:
+		return v.LtreeValue
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package okta
 import (
 	"context"
 	"slices"
-	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/okta/okta-sdk-golang/v2/okta/query"
@@ -21,9 +20,9 @@ func tableOktaApplicationAssignedGroup() *plugin.Table {
 		Name:        "okta_app_assigned_group",
 		Description: "Represents an application group assignment.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getApplicationAssignedGroup,
-			KeyColumns:        plugin.AllColumns([]string{"id", "app_id"}),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getApplicationAssignedGroup,
+			KeyColumns:   plugin.AllColumns([]string{"id", "app_id"}),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			ParentHydrate: getOrListOktaApplications,
@@ -92,47 +91,18 @@ func listApplicationAssignedGroups(ctx context.Context, d *plugin.QueryData, h *
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
-
-	groups, resp, err := client.Application.ListApplicationGroupAssignments(ctx, appId, &input)
-
+	input.Limit = pageSize(d, input.Limit)
+
+	paginator := newPaginatorV2(d, "listApplicationAssignedGroups", func() ([]*okta.ApplicationGroupAssignment, *okta.Response, error) {
+		return client.Application.ListApplicationGroupAssignments(ctx, appId, &input)
+	})
+	err = paginator.StreamFunc(ctx, func(group *okta.ApplicationGroupAssignment) interface{} {
+		return AppGroupInfo{appId, *group}
+	})
 	if err != nil {
-		logger.Error("listApplicationAssignedGroups", "list_app_groups_error", err)
 		return nil, err
 	}
 
-	for _, group := range groups {
-		d.StreamListItem(ctx, AppGroupInfo{appId, *group})
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextGroupSet []*okta.ApplicationGroupAssignment
-		resp, err = resp.Next(ctx, &nextGroupSet)
-		if err != nil {
-			logger.Error("listApplicationAssignedGroups", "list_app_groups_paging_error", err)
-			return nil, err
-		}
-		for _, group := range nextGroupSet {
-			d.StreamListItem(ctx, AppGroupInfo{appId, *group})
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
 	return nil, nil
 }
 
@@ -154,10 +124,11 @@ func getApplicationAssignedGroup(ctx context.Context, d *plugin.QueryData, h *pl
 		return nil, err
 	}
 
-	group, _, err := client.Application.GetApplicationGroupAssignment(ctx, appId, groupId, &query.Params{})
+	group, resp, err := client.Application.GetApplicationGroupAssignment(ctx, appId, groupId, &query.Params{})
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getApplicationAssignedGroup", "get_app_group_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return AppGroupInfo{appId, *group}, nil
@@ -176,18 +147,18 @@ func getOrListOktaApplications(ctx context.Context, d *plugin.QueryData, h *plug
 		// The okta_application table uses the "id" column instead
 		d.EqualsQuals["id"] = d.EqualsQuals["app_id"]
 		app, err := getOktaApplication(ctx, d, h)
-		if err != nil && !strings.Contains(err.Error(), "Not found") {
+		if err != nil && !isNotFoundError(err) {
 			logger.Error("getOrListOktaApplications", "get_application_error", err)
-			return nil, err
+			return nil, handleOktaError(d, err)
 		}
 		d.StreamListItem(ctx, app)
 		return nil, nil
 	}
 
-	_, err := listOktaApplications(ctx, d, h)
+	_, err := listCachedOktaApplications(ctx, d, h)
 	if err != nil {
 		logger.Error("getOrListOktaApplications", "list_applications_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return nil, nil
@@ -18,9 +18,9 @@ func tableOktaApplicationAssignedUser() *plugin.Table {
 		Name:        "okta_app_assigned_user",
 		Description: "Represents all assigned users for applications.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getApplicationAssignedUser,
-			KeyColumns:        plugin.AllColumns([]string{"id", "app_id"}),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getApplicationAssignedUser,
+			KeyColumns:   plugin.AllColumns([]string{"id", "app_id"}),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			ParentHydrate: getOrListOktaApplications,
@@ -97,47 +97,18 @@ func listApplicationAssignedUsers(ctx context.Context, d *plugin.QueryData, h *p
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
-
-	users, resp, err := client.Application.ListApplicationUsers(ctx, appId, input)
-
+	input.Limit = pageSize(d, input.Limit)
+
+	paginator := newPaginatorV2(d, "listApplicationAssignedUsers", func() ([]*okta.AppUser, *okta.Response, error) {
+		return client.Application.ListApplicationUsers(ctx, appId, input)
+	})
+	err = paginator.StreamFunc(ctx, func(user *okta.AppUser) interface{} {
+		return AppUserInfo{appId, *user}
+	})
 	if err != nil {
-		logger.Error("listApplicationAssignedUsers", "list_app_users_error", err)
 		return nil, err
 	}
 
-	for _, user := range users {
-		d.StreamListItem(ctx, AppUserInfo{appId, *user})
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextUserSet []*okta.AppUser
-		resp, err = resp.Next(ctx, &nextUserSet)
-		if err != nil {
-			logger.Error("listApplicationAssignedUsers", "list_app_users_paging_error", err)
-			return nil, err
-		}
-		for _, user := range nextUserSet {
-			d.StreamListItem(ctx, AppUserInfo{appId, *user})
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
 	return nil, nil
 }
 
@@ -159,10 +130,11 @@ func getApplicationAssignedUser(ctx context.Context, d *plugin.QueryData, h *plu
 		return nil, err
 	}
 
-	user, _, err := client.Application.GetApplicationUser(ctx, appId, userId, &query.Params{})
+	user, resp, err := client.Application.GetApplicationUser(ctx, appId, userId, &query.Params{})
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getApplicationAssignedUser", "get_app_user_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return AppUserInfo{appId, *user}, nil
@@ -19,9 +19,9 @@ func tableOktaApplication() *plugin.Table {
 		Name:        "okta_application",
 		Description: "An Application holds information about the protocol in which it wants Okta to communicate, policies for accessing the application, and which users can use the application after identifying themselves.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaApplication,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaApplication,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaApplications,
@@ -76,12 +76,7 @@ func listOktaApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
 	equalQuals := d.EqualsQuals
 	filter := buildQueryFilter(equalQuals, []string{"name", "status"})
@@ -97,43 +92,17 @@ func listOktaApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 		input.Filter = strings.Join(filter, " and ")
 	}
 
-	applications, resp, err := client.Application.ListApplications(ctx, &input)
-	if err != nil {
-		logger.Error("listOktaApplications", "list_applications_error", err)
-		if strings.Contains(err.Error(), "Not found") {
+	paginator := newPaginatorV2(d, "listOktaApplications", func() ([]*okta.Application, *okta.Response, error) {
+		return listApplications(ctx, client, &input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		if isNotFoundError(err) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	for _, app := range applications {
-		d.StreamListItem(ctx, app)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextApplicationSet []*okta.Application
-		resp, err = resp.Next(ctx, &nextApplicationSet)
-		if err != nil {
-			logger.Error("listOktaApplications", "list_applications_paging_error", err)
-			return nil, err
-		}
-		for _, app := range nextApplicationSet {
-			d.StreamListItem(ctx, app)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTION
@@ -154,11 +123,25 @@ func getOktaApplication(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 		return nil, err
 	}
 
-	app, _, err := client.Application.GetApplication(ctx, appId, okta.NewApplication(), &query.Params{})
+	app, resp, err := client.Application.GetApplication(ctx, appId, okta.NewApplication(), &query.Params{})
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaApplication", "get_application_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return app, nil
 }
+
+//// UTILITY FUNCTION
+
+// listApplications returns a page of applications as *okta.Application, which
+// is the type of every App returned by ListApplications.
+func listApplications(ctx context.Context, client *okta.Client, input *query.Params) ([]*okta.Application, *okta.Response, error) {
+	apps, resp, err := client.Application.ListApplications(ctx, input)
+	applications := make([]*okta.Application, 0, len(apps))
+	for _, app := range apps {
+		applications = append(applications, app.(*okta.Application))
+	}
+	return applications, resp, err
+}
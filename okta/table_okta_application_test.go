@@ -0,0 +1,41 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaApplicationList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_application"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaApplicationGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_application",
+		Quals: map[string]interface{}{"id": "0oa2slackxxxxxxxxxx2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaApplicationListRateLimited(t *testing.T) {
+	c := newTestConnection(t)
+	c.server.rateLimit("/api/v1/apps", 1)
+
+	rows, err := c.query(t, testQuery{Table: "okta_application", Columns: []string{"id", "name"}})
+	if err != nil {
+		t.Fatalf("expected the 429 to be retried, got %v", err)
+	}
+	if len(rows) != 3 {
+		t.Errorf("expected 3 rows, got %d", len(rows))
+	}
+}
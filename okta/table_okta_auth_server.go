@@ -2,7 +2,6 @@ package okta
 
 import (
 	"context"
-	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/okta/okta-sdk-golang/v2/okta/query"
@@ -19,9 +18,9 @@ func tableOktaAuthServer() *plugin.Table {
 		Name:        "okta_auth_server",
 		Description: "Represents an Okta Authorization Server.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaAuthServer,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaAuthServer,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaAuthServers,
@@ -75,50 +74,19 @@ func listOktaAuthServers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
-	servers, resp, err := client.AuthorizationServer.ListAuthorizationServers(ctx, &input)
-	if err != nil {
-		logger.Error("listOktaAuthServers", "list_auth_servers_error", err)
-		if strings.Contains(err.Error(), "Not found") {
+	paginator := newPaginatorV2(d, "listOktaAuthServers", func() ([]*okta.AuthorizationServer, *okta.Response, error) {
+		return client.AuthorizationServer.ListAuthorizationServers(ctx, &input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		if isNotFoundError(err) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	for _, server := range servers {
-		d.StreamListItem(ctx, server)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextAuthorizationServerSet []*okta.AuthorizationServer
-		resp, err = resp.Next(ctx, &nextAuthorizationServerSet)
-		if err != nil {
-			logger.Error("listOktaAuthServers", "list_auth_servers_paging_error", err)
-			return nil, err
-		}
-		for _, server := range nextAuthorizationServerSet {
-			d.StreamListItem(ctx, server)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -139,10 +107,11 @@ func getOktaAuthServer(ctx context.Context, d *plugin.QueryData, h *plugin.Hydra
 		return nil, err
 	}
 
-	server, _, err := client.AuthorizationServer.GetAuthorizationServer(ctx, authServerId)
+	server, resp, err := client.AuthorizationServer.GetAuthorizationServer(ctx, authServerId)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaAuthServer", "get_auth_servers_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return server, nil
@@ -57,38 +57,16 @@ func listOktaAuthenticationPolicies(ctx context.Context, d *plugin.QueryData, _
 		return nil, err
 	}
 
-	policies, resp, err := client.Policy.ListPolicies(ctx, input)
-	if err != nil {
-		logger.Error("listOktaAuthenticationPolicies", "list_policies_error", err)
+	// The policies API has no documented maximum page size, so a page size is
+	// only requested when the query has a limit
+	input.Limit = pageSize(d, 0)
+
+	paginator := newPaginatorV2(d, "listOktaAuthenticationPolicies", func() ([]*okta.AuthorizationServerPolicy, *okta.Response, error) {
+		return client.Policy.ListPolicies(ctx, input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
 		return nil, err
 	}
 
-	for _, policy := range policies {
-		d.StreamListItem(ctx, policy)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicySet []*okta.Policy
-		resp, err = resp.Next(ctx, &nextPolicySet)
-		if err != nil {
-			logger.Error("listOktaAuthenticationPolicies", "list_policies_paging_error", err)
-			return nil, err
-		}
-		for _, policy := range nextPolicySet {
-			d.StreamListItem(ctx, policy)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
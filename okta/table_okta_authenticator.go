@@ -17,9 +17,9 @@ func tableOktaAuthenticator() *plugin.Table {
 		Name:        "okta_authenticator",
 		Description: "Represents an Okta Authenticator configured in the organization.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaAuthenticator,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaAuthenticator,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaAuthenticators,
@@ -57,42 +57,14 @@ func listOktaAuthenticators(ctx context.Context, d *plugin.QueryData, _ *plugin.
 		return nil, err
 	}
 
-	req := client.AuthenticatorAPI.ListAuthenticators(ctx)
-	authenticators, resp, err := req.Execute()
+	paginator := newPaginatorV5(d, "okta_authenticator.listOktaAuthenticators", client.AuthenticatorAPI.ListAuthenticators(ctx).Execute)
+	err = paginator.StreamFunc(ctx, func(item oktaV5.ListAuthenticators200ResponseInner) interface{} {
+		return item.GetActualInstance()
+	})
 	if err != nil {
-		logger.Error("okta_authenticator.listOktaAuthenticators", "api_error", err)
 		return nil, err
 	}
 
-	for _, item := range authenticators {
-		if v := item.GetActualInstance(); v != nil {
-			d.StreamListItem(ctx, v)
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	// pagination
-	for resp.HasNextPage() {
-		var nextSet []oktaV5.ListAuthenticators200ResponseInner
-		resp, err = resp.Next(&nextSet)
-		if err != nil {
-			logger.Error("okta_authenticator.listOktaAuthenticators", "paging_error", err)
-			return nil, err
-		}
-		for _, item := range nextSet {
-			if v := item.GetActualInstance(); v != nil {
-				d.StreamListItem(ctx, v)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
-		}
-	}
-
 	return nil, nil
 }
 
@@ -115,7 +87,7 @@ func getOktaAuthenticator(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 	result, _, err := req.Execute()
 	if err != nil {
 		logger.Error("okta_authenticator.getOktaAuthenticator", "api_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	if result != nil {
@@ -0,0 +1,300 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	oktaV5 "github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+var (
+	// Method types of the authenticators, for enrollments that don't list
+	// their methods. Only the method types every enrollment of the
+	// authenticator has are listed.
+	authenticatorKeyMethodTypes = map[string][]string{
+		"custom_app":        {"push"},
+		"duo":               {"duo"},
+		"external_idp":      {"idp"},
+		"google_otp":        {"otp"},
+		"okta_email":        {"email"},
+		"okta_password":     {"password"},
+		"okta_verify":       {"push", "totp"},
+		"onprem_mfa":        {"otp"},
+		"phone_number":      {"sms", "voice"},
+		"security_key":      {"webauthn"},
+		"security_question": {"security_question"},
+		"smart_card_idp":    {"cert"},
+		"symantec_vip":      {"otp"},
+		"webauthn":          {"webauthn"},
+		"yubikey_token":     {"otp"},
+	}
+
+	// Method types that only some enrollments of the authenticators have, e.g.
+	// Okta Verify is only enrolled for FastPass on devices registered for it
+	authenticatorKeyOptionalMethodTypes = map[string][]string{
+		"okta_verify": {"signed_nonce"},
+	}
+
+	// Method types bound to the origin or the device they were enrolled on,
+	// i.e. Okta FastPass, WebAuthn and smart cards
+	phishingResistantMethodTypes = []string{"signed_nonce", "webauthn", "cert"}
+)
+
+//// TABLE DEFINITION
+
+func tableOktaAuthenticatorEnrollment() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_authenticator_enrollment",
+		Description: "Represents an Identity Engine authenticator enrolled by an Okta user, e.g. Okta Verify, a phone or a passkey.",
+		List: &plugin.ListConfig{
+			Hydrate: listOktaAuthenticatorEnrollments,
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "user_id", Require: plugin.Optional},
+				{Name: "user_name", Require: plugin.Optional},
+				{Name: "user_email", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "id", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Id"), Description: "Unique identifier of the enrollment."},
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "ID of the user who enrolled the authenticator."},
+			{Name: "user_name", Type: proto.ColumnType_STRING, Description: "Login of the user who enrolled the authenticator."},
+			{Name: "user_email", Type: proto.ColumnType_STRING, Description: "Primary email address of the user who enrolled the authenticator."},
+			{Name: "authenticator_id", Type: proto.ColumnType_STRING, Description: "ID of the enrolled authenticator, to join with the okta_authenticator table."},
+			{Name: "key", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Key"), Description: "Key of the enrolled authenticator, e.g. okta_verify, phone_number or webauthn."},
+
+			// Other Columns
+			{Name: "type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Type"), Description: "Type of the enrolled authenticator, e.g. app, phone or security_key."},
+			{Name: "name", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Name"), Description: "Display name of the enrolled authenticator."},
+			{Name: "nickname", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Nickname").NullIfZero(), Description: "Nickname given to the enrollment by the user."},
+			{Name: "status", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Status"), Description: "Status of the enrollment, e.g. ACTIVE or INACTIVE."},
+			{Name: "phishing_resistant", Type: proto.ColumnType_BOOL, Description: "True if one of the methods of the enrollment is phishing resistant, i.e. Okta FastPass (signed_nonce), WebAuthn or a smart card. Null if the enrollment doesn't list its methods and may include FastPass."},
+			{Name: "user_verification", Type: proto.ColumnType_STRING, Description: "User verification of the enrollment, e.g. REQUIRED if biometrics or a PIN are required to use it. Null if not reported by Okta."},
+			{Name: "created", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Enrollment.Created"), Description: "Timestamp when the authenticator was enrolled."},
+			{Name: "last_updated", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Enrollment.LastUpdated"), Description: "Timestamp when the enrollment was last updated."},
+			{Name: "last_used", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Enrollment.LastUsed"), Description: "Timestamp when the enrollment was last used to authenticate. Null if not reported by Okta."},
+
+			// JSON Columns
+			{Name: "method_types", Type: proto.ColumnType_JSON, Description: "Method types of the enrollment, e.g. push, totp and signed_nonce for Okta Verify. Derived from the authenticator key if the enrollment doesn't list its methods, in which case signed_nonce isn't included."},
+			{Name: "methods", Type: proto.ColumnType_JSON, Transform: transform.FromField("Enrollment.Methods"), Description: "Methods of the enrollment, as returned by Okta."},
+			{Name: "profile", Type: proto.ColumnType_JSON, Transform: transform.FromField("Enrollment.Profile"), Description: "Profile of the enrollment, e.g. the phone number or the device name."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Enrollment.Name"), Description: titleDescription},
+		}),
+	}
+}
+
+type UserAuthenticatorEnrollment struct {
+	UserId            string
+	UserName          string
+	UserEmail         string
+	AuthenticatorId   string
+	MethodTypes       []string
+	PhishingResistant *bool
+	UserVerification  *string
+	Enrollment        *AuthenticatorEnrollment
+}
+
+// AuthenticatorEnrollment is returned by the authenticator enrollments
+// endpoint, which none of the SDKs implement.
+type AuthenticatorEnrollment struct {
+	Id          string                          `json:"id,omitempty"`
+	Type        string                          `json:"type,omitempty"`
+	Key         string                          `json:"key,omitempty"`
+	Status      string                          `json:"status,omitempty"`
+	Name        string                          `json:"name,omitempty"`
+	Nickname    string                          `json:"nickname,omitempty"`
+	Created     *time.Time                      `json:"created,omitempty"`
+	LastUpdated *time.Time                      `json:"lastUpdated,omitempty"`
+	LastUsed    *time.Time                      `json:"lastUsed,omitempty"`
+	Profile     map[string]interface{}          `json:"profile,omitempty"`
+	Methods     []AuthenticatorEnrollmentMethod `json:"methods,omitempty"`
+	Links       map[string]interface{}          `json:"_links,omitempty"`
+}
+
+type AuthenticatorEnrollmentMethod struct {
+	Type             string `json:"type,omitempty"`
+	Status           string `json:"status,omitempty"`
+	UserVerification string `json:"userVerification,omitempty"`
+}
+
+//// LIST FUNCTION
+
+func listOktaAuthenticatorEnrollments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	users, err := listUsersMatchingQuals(ctx, d)
+	if err != nil {
+		logger.Error("okta_authenticator_enrollment.listOktaAuthenticatorEnrollments", "list_users_error", err)
+		return nil, err
+	}
+
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("okta_authenticator_enrollment.listOktaAuthenticatorEnrollments", "connect_error", err)
+		return nil, err
+	}
+
+	authenticatorIds, err := getAuthenticatorIdsByKey(ctx, d)
+	if err != nil {
+		logger.Error("okta_authenticator_enrollment.listOktaAuthenticatorEnrollments", "list_authenticators_error", err)
+		return nil, err
+	}
+
+	err = forEachUserConcurrently(ctx, d, "okta_authenticator_enrollment.listOktaAuthenticatorEnrollments", users, func(user *okta.User) error {
+		userName, userEmail := userLoginAndEmail(user)
+
+		paginator := newPaginatorV2(d, "okta_authenticator_enrollment.listOktaAuthenticatorEnrollments", func() ([]*AuthenticatorEnrollment, *okta.Response, error) {
+			return listAuthenticatorEnrollments(ctx, client, user.Id)
+		})
+		err := paginator.StreamFunc(ctx, func(enrollment *AuthenticatorEnrollment) interface{} {
+			authenticatorId := linkedResourceId(enrollment.Links, "authenticator")
+			if authenticatorId == "" {
+				authenticatorId = authenticatorIds[enrollment.Key]
+			}
+			return UserAuthenticatorEnrollment{
+				UserId:            user.Id,
+				UserName:          userName,
+				UserEmail:         userEmail,
+				AuthenticatorId:   authenticatorId,
+				MethodTypes:       enrollmentMethodTypes(enrollment),
+				PhishingResistant: enrollmentPhishingResistant(enrollment),
+				UserVerification:  enrollmentUserVerification(enrollment),
+				Enrollment:        enrollment,
+			}
+		})
+		// The user may have been deleted since it was listed
+		if err != nil && !isNotFoundError(err) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserAuthenticatorEnrollments/
+func listAuthenticatorEnrollments(ctx context.Context, client *okta.Client, userId string) ([]*AuthenticatorEnrollment, *okta.Response, error) {
+	url := fmt.Sprintf("/api/v1/users/%v/authenticator-enrollments", userId)
+
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var enrollments []*AuthenticatorEnrollment
+
+	resp, err := requestExecutor.Do(ctx, req, &enrollments)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return enrollments, resp, nil
+}
+
+//// UTILITY FUNCTIONS
+
+// getAuthenticatorIdsByKey maps the keys of the authenticators of the org to
+// their IDs, for enrollments without a link to their authenticator. The key
+// of an authenticator is unique within an org.
+func getAuthenticatorIdsByKey(ctx context.Context, d *plugin.QueryData) (map[string]string, error) {
+	client, err := ConnectV5(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticators, err := getCachedList(ctx, d, "listCachedOktaAuthenticators", func() ([]oktaV5.ListAuthenticators200ResponseInner, error) {
+		paginator := newPaginatorV5(d, "listCachedOktaAuthenticators", client.AuthenticatorAPI.ListAuthenticators(ctx).Execute)
+		authenticators, err := paginator.All(ctx)
+		if err != nil && isNotFoundError(err) {
+			return nil, nil
+		}
+		return authenticators, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]string{}
+	for _, authenticator := range authenticators {
+		data, err := json.Marshal(authenticator.GetActualInstance())
+		if err != nil {
+			return nil, err
+		}
+		var base oktaV5.AuthenticatorBase
+		if err := json.Unmarshal(data, &base); err != nil {
+			return nil, err
+		}
+		ids[base.GetKey()] = base.GetId()
+	}
+	return ids, nil
+}
+
+// enrollmentMethodTypes returns the method types listed by the enrollment, or
+// else the method types of its authenticator.
+func enrollmentMethodTypes(enrollment *AuthenticatorEnrollment) []string {
+	if len(enrollment.Methods) == 0 {
+		return authenticatorKeyMethodTypes[enrollment.Key]
+	}
+	var methodTypes []string
+	for _, method := range enrollment.Methods {
+		if method.Type != "" && !slices.Contains(methodTypes, method.Type) {
+			methodTypes = append(methodTypes, method.Type)
+		}
+	}
+	return methodTypes
+}
+
+// enrollmentPhishingResistant returns whether one of the methods of the
+// enrollment is phishing resistant, or nil if the enrollment doesn't list its
+// methods and its authenticator may have a phishing-resistant method.
+func enrollmentPhishingResistant(enrollment *AuthenticatorEnrollment) *bool {
+	isPhishingResistant := func(methodType string) bool {
+		return slices.Contains(phishingResistantMethodTypes, methodType)
+	}
+
+	phishingResistant := slices.ContainsFunc(enrollmentMethodTypes(enrollment), isPhishingResistant)
+	if !phishingResistant && len(enrollment.Methods) == 0 && slices.ContainsFunc(authenticatorKeyOptionalMethodTypes[enrollment.Key], isPhishingResistant) {
+		return nil
+	}
+	return &phishingResistant
+}
+
+func enrollmentUserVerification(enrollment *AuthenticatorEnrollment) *string {
+	for _, method := range enrollment.Methods {
+		if method.UserVerification != "" {
+			return &method.UserVerification
+		}
+	}
+	if userVerification, ok := enrollment.Profile["userVerification"].(string); ok && userVerification != "" {
+		return &userVerification
+	}
+	return nil
+}
+
+// linkedResourceId returns the ID of the resource of a HAL link, e.g. aut1 for
+// {"authenticator": {"href": "https://example.okta.com/api/v1/authenticators/aut1"}}
+func linkedResourceId(links map[string]interface{}, name string) string {
+	link, _ := links[name].(map[string]interface{})
+	href, _ := link["href"].(string)
+	u, err := url.Parse(href)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return path.Base(u.Path)
+}
@@ -0,0 +1,33 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaAuthenticatorEnrollmentList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_authenticator_enrollment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaAuthenticatorEnrollmentListByUser(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_authenticator_enrollment",
+		Columns: []string{"id", "user_id", "authenticator_id", "key", "method_types", "phishing_resistant", "user_verification"},
+		Quals:   map[string]interface{}{"user_id": "00u3carolxxxxxxxxxx3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 0 {
+		t.Errorf("expected the users not to be listed, got %v", c.server.Requests())
+	}
+}
@@ -0,0 +1,222 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOktaAuthenticatorMethod() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_authenticator_method",
+		Description: "Represents a method of an Okta authenticator, e.g. the push or FastPass method of Okta Verify, with its settings.",
+		List: &plugin.ListConfig{
+			Hydrate:       listOktaAuthenticatorMethods,
+			ParentHydrate: listOktaAuthenticators,
+			KeyColumns:    plugin.OptionalColumns([]string{"authenticator_id"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "authenticator_id", Type: proto.ColumnType_STRING, Description: "Unique identifier of the authenticator."},
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "Type of the method, e.g. push, signed_nonce, totp, sms, voice, email or webauthn."},
+			{Name: "status", Type: proto.ColumnType_STRING, Description: "Status of the method (ACTIVE or INACTIVE)."},
+
+			// Other Columns
+			{Name: "authenticator_key", Type: proto.ColumnType_STRING, Description: "Key of the authenticator, e.g. okta_verify or phone_number."},
+			{Name: "authenticator_name", Type: proto.ColumnType_STRING, Description: "Display name of the authenticator."},
+			{Name: "authenticator_status", Type: proto.ColumnType_STRING, Description: "Status of the authenticator (ACTIVE or INACTIVE)."},
+			{Name: "enabled", Type: proto.ColumnType_BOOL, Transform: transform.From(authenticatorMethodSetting), Description: "True if both the method and its authenticator are active."},
+			{Name: "key_protection", Type: proto.ColumnType_STRING, Transform: transform.From(authenticatorMethodSetting), Description: "Storage required for the keys of Okta Verify push and FastPass: ANY or HARDWARE."},
+			{Name: "user_verification", Type: proto.ColumnType_STRING, Transform: transform.From(authenticatorMethodSetting), Description: "User verification, e.g. biometrics or a PIN, required by the Okta Verify push and FastPass methods, or by WebAuthn: DISCOURAGED, PREFERRED or REQUIRED."},
+			{Name: "number_challenge", Type: proto.ColumnType_STRING, Transform: transform.From(authenticatorMethodSetting), Description: "When Okta Verify push requires a number challenge: ALWAYS, HIGH_RISK_ONLY or NEVER."},
+			{Name: "show_sign_in_with_ov", Type: proto.ColumnType_STRING, Transform: transform.From(authenticatorMethodSetting), Description: "When the sign-in page shows the Sign in with Okta Verify button for FastPass: ALWAYS or NEVER."},
+			{Name: "attachment", Type: proto.ColumnType_STRING, Transform: transform.From(authenticatorMethodSetting), Description: "Kind of WebAuthn authenticators allowed: ANY, BUILT_IN or ROAMING."},
+			{Name: "token_lifetime_in_minutes", Type: proto.ColumnType_INT, Transform: transform.From(authenticatorMethodSetting), Description: "Lifetime of the email magic links and codes, in minutes."},
+
+			// JSON Columns
+			{Name: "settings", Type: proto.ColumnType_JSON, Description: "Settings of the method."},
+			{Name: "authenticator_settings", Type: proto.ColumnType_JSON, Description: "Settings of the authenticator."},
+			{Name: "algorithms", Type: proto.ColumnType_JSON, Transform: transform.From(authenticatorMethodSetting), Description: "Key algorithms allowed by the Okta Verify push and FastPass methods."},
+			{Name: "transaction_types", Type: proto.ColumnType_JSON, Transform: transform.From(authenticatorMethodSetting), Description: "Transaction types supported by Okta Verify push, e.g. LOGIN or CIBA."},
+			{Name: "aaguid_groups", Type: proto.ColumnType_JSON, Transform: transform.From(authenticatorMethodSetting), Description: "Named groups of the WebAuthn authenticator models allowed to enroll. All models are allowed if empty."},
+			{Name: "aaguids", Type: proto.ColumnType_JSON, Transform: transform.From(authenticatorMethodSetting), Description: "AAGUIDs of the WebAuthn authenticator models allowed to enroll, from all the AAGUID groups."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Type"), Description: titleDescription},
+		}),
+	}
+}
+
+// AuthenticatorMethod is returned by the authenticator methods endpoint,
+// whose settings the SDK types only cover for some methods.
+type AuthenticatorMethod struct {
+	AuthenticatorId       string                 `json:"-"`
+	AuthenticatorKey      string                 `json:"-"`
+	AuthenticatorName     string                 `json:"-"`
+	AuthenticatorStatus   string                 `json:"-"`
+	AuthenticatorSettings map[string]interface{} `json:"-"`
+	Type                  string                 `json:"type,omitempty"`
+	Status                string                 `json:"status,omitempty"`
+	Settings              map[string]interface{} `json:"settings,omitempty"`
+}
+
+// authenticatorSummary holds the fields common to the authenticator types of
+// the v5 SDK.
+type authenticatorSummary struct {
+	Id       string                 `json:"id,omitempty"`
+	Key      string                 `json:"key,omitempty"`
+	Name     string                 `json:"name,omitempty"`
+	Status   string                 `json:"status,omitempty"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+//// LIST FUNCTION
+
+func listOktaAuthenticatorMethods(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	// The authenticators are of a different type for every key
+	data, err := json.Marshal(h.Item)
+	if err != nil {
+		return nil, err
+	}
+	var authenticator authenticatorSummary
+	if err := json.Unmarshal(data, &authenticator); err != nil {
+		return nil, err
+	}
+
+	// Restrict API call based on authenticator_id query parameter.
+	if authenticatorId := d.EqualsQualString("authenticator_id"); authenticatorId != "" && authenticatorId != authenticator.Id {
+		return nil, nil
+	}
+
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("okta_authenticator_method.listOktaAuthenticatorMethods", "connect_error", err)
+		return nil, err
+	}
+
+	paginator := newPaginatorV2(d, "okta_authenticator_method.listOktaAuthenticatorMethods", func() ([]*AuthenticatorMethod, *okta.Response, error) {
+		return listAuthenticatorMethods(ctx, client, authenticator.Id)
+	})
+	err = paginator.ForEach(ctx, func(method *AuthenticatorMethod) bool {
+		method.AuthenticatorId = authenticator.Id
+		method.AuthenticatorKey = authenticator.Key
+		method.AuthenticatorName = authenticator.Name
+		method.AuthenticatorStatus = authenticator.Status
+		method.AuthenticatorSettings = authenticator.Settings
+		d.StreamListItem(ctx, method)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	// The authenticator may have been deleted since it was listed
+	if err != nil && !isNotFoundError(err) {
+		logger.Error("okta_authenticator_method.listOktaAuthenticatorMethods", "api_error", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Authenticator/#tag/Authenticator/operation/listAuthenticatorMethods
+func listAuthenticatorMethods(ctx context.Context, client *okta.Client, authenticatorId string) ([]*AuthenticatorMethod, *okta.Response, error) {
+	url := fmt.Sprintf("/api/v1/authenticators/%v/methods", authenticatorId)
+
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var methods []*AuthenticatorMethod
+
+	resp, err := requestExecutor.Do(ctx, req, &methods)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return methods, resp, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// authenticatorMethodSetting returns the typed settings of a method, named
+// after the column. Some settings of the Okta Verify and email methods are
+// settings of their authenticator.
+func authenticatorMethodSetting(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	method, ok := d.HydrateItem.(*AuthenticatorMethod)
+	if !ok || method == nil {
+		return nil, nil
+	}
+	isOktaVerify := method.AuthenticatorKey == "okta_verify" && (method.Type == "push" || method.Type == "signed_nonce")
+
+	switch d.ColumnName {
+	case "enabled":
+		return method.Status == "ACTIVE" && method.AuthenticatorStatus == "ACTIVE", nil
+	case "key_protection":
+		if method.Type == "push" || method.Type == "signed_nonce" {
+			return method.Settings["keyProtection"], nil
+		}
+	case "algorithms":
+		if method.Type == "push" || method.Type == "signed_nonce" {
+			return method.Settings["algorithms"], nil
+		}
+	case "transaction_types":
+		if method.Type == "push" {
+			return method.Settings["transactionTypes"], nil
+		}
+	case "user_verification":
+		if method.Type == "webauthn" {
+			return method.Settings["userVerification"], nil
+		}
+		if isOktaVerify {
+			return method.AuthenticatorSettings["userVerification"], nil
+		}
+	case "number_challenge":
+		if isOktaVerify && method.Type == "push" {
+			channelBinding, _ := method.AuthenticatorSettings["channelBinding"].(map[string]interface{})
+			if channelBinding["style"] == "NUMBER_CHALLENGE" {
+				return channelBinding["required"], nil
+			}
+		}
+	case "show_sign_in_with_ov":
+		if method.Type == "signed_nonce" {
+			return method.Settings["showSignInWithOV"], nil
+		}
+	case "attachment":
+		if method.Type == "webauthn" {
+			return method.Settings["attachment"], nil
+		}
+	case "token_lifetime_in_minutes":
+		if method.AuthenticatorKey == "okta_email" && method.Type == "email" {
+			return method.AuthenticatorSettings["tokenLifetimeInMinutes"], nil
+		}
+	case "aaguid_groups":
+		if method.Type == "webauthn" {
+			return method.Settings["aaguidGroups"], nil
+		}
+	case "aaguids":
+		if method.Type == "webauthn" {
+			groups, _ := method.Settings["aaguidGroups"].([]interface{})
+			var aaguids []interface{}
+			for _, group := range groups {
+				if group, ok := group.(map[string]interface{}); ok {
+					groupAaguids, _ := group["aaguids"].([]interface{})
+					aaguids = append(aaguids, groupAaguids...)
+				}
+			}
+			return aaguids, nil
+		}
+	}
+
+	return nil, nil
+}
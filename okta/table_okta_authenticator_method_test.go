@@ -0,0 +1,32 @@
+package okta
+
+import "testing"
+
+func TestOktaAuthenticatorMethodList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_authenticator_method",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaAuthenticatorMethodListByAuthenticator(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_authenticator_method",
+		Columns: []string{"authenticator_id", "type", "status", "enabled"},
+		Quals:   map[string]interface{}{"authenticator_id": "aut4phonexxxxxxxxxx4"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+	if n := c.server.countRequests("GET /api/v1/authenticators/aut"); n != 1 {
+		t.Errorf("expected the methods of one authenticator to be listed, got %d requests", n)
+	}
+}
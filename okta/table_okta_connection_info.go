@@ -0,0 +1,168 @@
+package okta
+
+import (
+	"context"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOktaConnectionInfo() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_connection_info",
+		Description: "Diagnostic information about the Okta connection, including the authenticated principal, granted scopes and rate limits.",
+		List: &plugin.ListConfig{
+			Hydrate: listOktaConnectionInfo,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "auth_mode", Type: proto.ColumnType_STRING, Description: "The authorization mode used by the connection: SSWS (API token) or PrivateKey (service app)."},
+			{Name: "principal_type", Type: proto.ColumnType_STRING, Description: "The type of the authenticated principal: USER for API tokens or SERVICE_APP for service apps."},
+			{Name: "principal_id", Type: proto.ColumnType_STRING, Description: "The ID of the user that owns the API token, or the client ID of the service app."},
+			{Name: "principal_name", Type: proto.ColumnType_STRING, Description: "The login of the user that owns the API token, or the label of the service app."},
+
+			// Other Columns
+			{Name: "org_id", Type: proto.ColumnType_STRING, Description: "The ID of the Okta organization."},
+			{Name: "subdomain", Type: proto.ColumnType_STRING, Description: "The subdomain of the Okta organization."},
+			{Name: "pipeline", Type: proto.ColumnType_STRING, Description: "The authentication pipeline of the organization: idx for Identity Engine or v1 for Classic Engine."},
+			{Name: "is_identity_engine", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Pipeline").Transform(isIdentityEnginePipeline), Description: "True if the organization runs Okta Identity Engine (OIE)."},
+
+			// JSON Columns
+			{Name: "granted_scopes", Type: proto.ColumnType_JSON, Description: "The OAuth scopes granted to the service app in its access token. Null for API tokens, which have the permissions of the user that created them."},
+			{Name: "assigned_roles", Type: proto.ColumnType_JSON, Description: "The admin roles assigned to the user that owns the API token."},
+			{Name: "rate_limits", Type: proto.ColumnType_JSON, Description: "The most recent X-Rate-Limit-Limit, X-Rate-Limit-Remaining and X-Rate-Limit-Reset values seen by the connection, per endpoint."},
+			{Name: "pagination", Type: proto.ColumnType_JSON, Description: "The number of calls, pages and items fetched by each list function of the plugin since it started."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("PrincipalName"), Description: titleDescription},
+		}),
+	}
+}
+
+type ConnectionInfo struct {
+	AuthMode      string
+	PrincipalType string
+	PrincipalId   string
+	PrincipalName string
+	OrgId         string
+	Subdomain     string
+	Pipeline      string
+	GrantedScopes []string
+	AssignedRoles []string
+	RateLimits    []rateLimitInfo
+	Pagination    []paginationInfo
+}
+
+// Response of the well-known organization endpoint
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/OrgSetting/#tag/OrgSetting/operation/getWellknownOrgMetadata
+type orgMetadata struct {
+	Id       string `json:"id,omitempty"`
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+//// LIST FUNCTION
+
+func listOktaConnectionInfo(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("okta_connection_info.listOktaConnectionInfo", "connect_error", err)
+		return nil, err
+	}
+
+	config := client.GetConfig()
+	info := ConnectionInfo{
+		AuthMode:  config.Okta.Client.AuthorizationMode,
+		Subdomain: strings.Split(normalizeOktaDomain(config.Okta.Client.OrgUrl), ".")[0],
+	}
+
+	metadata, err := getOktaOrgMetadata(ctx, client)
+	if err != nil {
+		logger.Error("okta_connection_info.listOktaConnectionInfo", "get_org_metadata_error", err)
+		return nil, handleOktaError(d, err)
+	}
+	info.OrgId = metadata.Id
+	info.Pipeline = metadata.Pipeline
+
+	if info.AuthMode == "PrivateKey" {
+		info.PrincipalType = "SERVICE_APP"
+		info.PrincipalId = config.Okta.Client.ClientId
+
+		// The service app may not have been granted okta.apps.read, which shouldn't
+		// prevent the rest of the diagnostics from being returned
+		app, resp, err := client.Application.GetApplication(ctx, info.PrincipalId, okta.NewApplication(), &query.Params{})
+		err = withResponseStatus(err, resp)
+		if err != nil {
+			logger.Warn("okta_connection_info.listOktaConnectionInfo", "get_application_error", err)
+		} else if app, ok := app.(*okta.Application); ok {
+			info.PrincipalName = app.Label
+		}
+	} else {
+		info.PrincipalType = "USER"
+
+		user, resp, err := client.User.GetUser(ctx, "me")
+		err = withResponseStatus(err, resp)
+		if err != nil {
+			logger.Error("okta_connection_info.listOktaConnectionInfo", "get_user_error", err)
+			return nil, handleOktaError(d, err)
+		}
+		info.PrincipalId = user.Id
+		if user.Profile != nil {
+			if login, ok := (*user.Profile)["login"].(string); ok {
+				info.PrincipalName = login
+			}
+		}
+
+		roles, resp, err := client.User.ListAssignedRolesForUser(ctx, user.Id, &query.Params{})
+		err = withResponseStatus(err, resp)
+		if err != nil {
+			logger.Warn("okta_connection_info.listOktaConnectionInfo", "list_assigned_roles_for_user_error", err)
+		}
+		for _, role := range roles {
+			info.AssignedRoles = append(info.AssignedRoles, role.Type)
+		}
+	}
+
+	// Read the stats last so that they include the calls made above
+	stats := getConnectionStats(d)
+	info.GrantedScopes = stats.GrantedScopes()
+	info.RateLimits = stats.RateLimits()
+	info.Pagination = stats.Pagination()
+
+	d.StreamListItem(ctx, info)
+
+	return nil, nil
+}
+
+func getOktaOrgMetadata(ctx context.Context, client *okta.Client) (*orgMetadata, error) {
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", "/.well-known/okta-organization", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata orgMetadata
+	resp, err := requestExecutor.Do(ctx, req, &metadata)
+	err = withResponseStatus(err, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+//// TRANSFORM FUNCTION
+
+func isIdentityEnginePipeline(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	pipeline, ok := d.Value.(string)
+	if !ok || pipeline == "" {
+		return nil, nil
+	}
+	return pipeline == "idx", nil
+}
@@ -3,7 +3,6 @@ package okta
 import (
 	"context"
 
-	"github.com/okta/okta-sdk-golang/v4/okta"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 
@@ -17,9 +16,9 @@ func tableOktaDevice() *plugin.Table {
 		Name:        "okta_device",
 		Description: "Okta’s device management is a crucial part of its broader suite of identity and access management solutions, helping organizations to secure their IT environments in an increasingly mobile and cloud-centric world.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaDevice,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaDevice,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaDevices,
@@ -110,15 +109,9 @@ func listOktaDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrate
 
 	// Default maximum limit set as per documentation
 	// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/#tag/Device/operation/listDevices!in=query&path=limit&t=request
-	maxLimit := int64(20)
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < maxLimit {
-			maxLimit = *limit
-		}
-	}
+	maxLimit := pageSize(d, 20)
 
 	searchParam := buildDeviceFilterParam(d)
 
@@ -128,40 +121,12 @@ func listOktaDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrate
 		deviceReq = deviceReq.Search(searchParam)
 	}
 
-	devices, resp, err := deviceReq.Execute()
-	if err != nil {
-		logger.Error("okta_device.listOktaDevices", "api_error", err)
+	paginator := newPaginatorV4(d, "okta_device.listOktaDevices", deviceReq.Execute)
+	if err := paginator.Stream(ctx); err != nil {
 		return nil, err
 	}
 
-	for _, device := range devices {
-		d.StreamListItem(ctx, device)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextDeviceSet []okta.DeviceList
-		resp, err = resp.Next(&nextDeviceSet)
-		if err != nil {
-			logger.Error("okta_device.listOktaDevices", "paging_error", err)
-			return nil, err
-		}
-		for _, device := range nextDeviceSet {
-			d.StreamListItem(ctx, device)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -185,7 +150,7 @@ func getOktaDevice(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 	result, _, err := deviceReq.Execute()
 	if err != nil {
 		logger.Error("okta_device.getOktaDevice", "api_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	if result != nil {
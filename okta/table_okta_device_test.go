@@ -0,0 +1,48 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaDeviceList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_device"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// 3 devices served 2 per page
+	if n := c.server.countRequests("GET /api/v1/devices?"); n != 2 {
+		t.Errorf("expected the devices to be listed in 2 pages, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaDeviceGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_device",
+		Quals: map[string]interface{}{"id": "guo3carolphonexxxxx3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaDeviceGetNotFound(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_device",
+		Quals: map[string]interface{}{"id": "guo9missingxxxxxxxx9"},
+	})
+	if err != nil {
+		t.Fatalf("expected the 404 to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
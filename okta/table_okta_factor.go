@@ -2,12 +2,9 @@ package okta
 
 import (
 	"context"
-	"slices"
-	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	oktav4 "github.com/okta/okta-sdk-golang/v4/okta"
-	"github.com/turbot/go-kit/types"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 
@@ -21,15 +18,16 @@ func tableOktaFactor() *plugin.Table {
 		Name:        "okta_factor",
 		Description: "Represents an Okta Factor.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaFactor,
-			KeyColumns:        plugin.AllColumns([]string{"id", "user_id"}),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found", "Invalid Factor"}),
+			Hydrate:      getOktaFactor,
+			KeyColumns:   plugin.AllColumns([]string{"id", "user_id"}),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError, isInvalidFactorError)},
 		},
 		List: &plugin.ListConfig{
-			ParentHydrate: listOktaUsers,
-			Hydrate:       listOktaFactors,
+			Hydrate: listOktaFactors,
 			KeyColumns: []*plugin.KeyColumn{
 				{Name: "user_id", Require: plugin.Optional},
+				{Name: "user_name", Require: plugin.Optional},
+				{Name: "user_email", Require: plugin.Optional},
 			},
 		},
 		Columns: commonColumns([]*plugin.Column{
@@ -37,6 +35,7 @@ func tableOktaFactor() *plugin.Table {
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "Unique key for Group.", Transform: transform.FromField("Factor.Id")},
 			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "Unique key for Group."},
 			{Name: "user_name", Type: proto.ColumnType_STRING, Description: "Unique identifier for the user (username)."},
+			{Name: "user_email", Type: proto.ColumnType_STRING, Description: "Primary email address of the user."},
 			{Name: "factor_type", Type: proto.ColumnType_STRING, Description: "Description of the Group.", Transform: transform.FromField("Factor.FactorType")},
 			{Name: "created", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when Group was created.", Transform: transform.FromField("Factor.Created")},
 
@@ -57,9 +56,10 @@ func tableOktaFactor() *plugin.Table {
 }
 
 type UserFactorInfo struct {
-	UserId   string
-	UserName string
-	Factor   OktaFactor
+	UserId    string
+	UserName  string
+	UserEmail string
+	Factor    OktaFactor
 }
 
 type OktaFactor struct {
@@ -69,94 +69,54 @@ type OktaFactor struct {
 
 //// LIST FUNCTION
 
-func listOktaFactors(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+func listOktaFactors(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	logger := plugin.Logger(ctx)
-	client, err := ConnectV4(ctx, d)
+
+	// Only the users matching the user_id, user_name and user_email quals are
+	// fetched, rather than every user of the org
+	users, err := listUsersMatchingQuals(ctx, d)
 	if err != nil {
-		logger.Error("okta_factor.listOktaFactors", "connect_error", err)
+		logger.Error("okta_factor.listOktaFactors", "list_users_error", err)
 		return nil, err
 	}
 
-	var userId string
-	var userName string
-	if h.Item != nil {
-		userData := h.Item.(*okta.User)
-		userId = userData.Id
-		userProfile := *userData.Profile
-		userName = userProfile["login"].(string)
-	}
-
-	// Minimize the API call with the given user id
-	if d.EqualsQuals["user_id"] != nil {
-		if d.EqualsQualString("user_id") != "" {
-			if d.EqualsQualString("user_id") != "" && d.EqualsQualString("user_id") != userId {
-				return nil, nil
-			}
-		} else if len(getListValues(d.EqualsQuals["user_id"].GetListValue())) > 0 {
-			if !slices.Contains(types.StringValueSlice(getListValues(d.EqualsQuals["user_id"].GetListValue())), userId) {
-				return nil, nil
-			}
-		}
-	}
-
-	if userId == "" {
-		return nil, nil
-	}
-
-	factorReq := client.UserFactorAPI.ListFactors(ctx, userId)
-
-	factors, resp, err := factorReq.Execute()
+	client, err := ConnectV4(ctx, d)
 	if err != nil {
-		logger.Error("okta_factor.listOktaFactors", "api_error", err)
-		if strings.Contains(err.Error(), "Not found") {
-			return nil, nil
-		}
+		logger.Error("okta_factor.listOktaFactors", "connect_error", err)
 		return nil, err
 	}
 
-	for _, factor := range factors {
-		if factor.GetActualInstance() != nil {
-			factorDetails := getFactorDetails(factor.GetActualInstance())
-			d.StreamListItem(ctx, UserFactorInfo{
-				UserId:   userId,
-				UserName: userName,
-				Factor:   factorDetails,
-			})
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
+	err = forEachUserConcurrently(ctx, d, "okta_factor.listOktaFactors", users, func(user *okta.User) error {
+		return streamUserFactors(ctx, d, client, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// paging
-	for resp.HasNextPage() {
-		var nextFactorSet []oktav4.ListFactors200ResponseInner
-		resp, err = resp.Next(&nextFactorSet)
-		if err != nil {
-			logger.Error("okta_factor.listOktaFactors", "api_paging_error", err)
-			return nil, err
-		}
-
-		for _, factor := range nextFactorSet {
-			if factor.GetActualInstance() != nil {
-				f := getFactorDetails(factor.GetActualInstance())
-				d.StreamListItem(ctx, UserFactorInfo{
-					UserId:   userId,
-					UserName: userName,
-					Factor:   f,
-				})
+	return nil, nil
+}
 
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
-		}
+// streamUserFactors streams every factor enrolled by the user.
+func streamUserFactors(ctx context.Context, d *plugin.QueryData, client *oktav4.APIClient, user *okta.User) error {
+	userName, userEmail := userLoginAndEmail(user)
+
+	paginator := newPaginatorV4(d, "okta_factor.listOktaFactors", client.UserFactorAPI.ListFactors(ctx, user.Id).Execute)
+	err := paginator.StreamFunc(ctx, func(factor oktav4.ListFactors200ResponseInner) interface{} {
+		if factor.GetActualInstance() == nil {
+			return nil
+		}
+		return UserFactorInfo{
+			UserId:    user.Id,
+			UserName:  userName,
+			UserEmail: userEmail,
+			Factor:    getFactorDetails(factor.GetActualInstance()),
+		}
+	})
+	// The user may have been deleted since it was listed
+	if err != nil && !isNotFoundError(err) {
+		return err
 	}
-
-	return nil, err
+	return nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -180,20 +140,23 @@ func getOktaFactor(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 	user, _, err := userReq.Execute()
 	if err != nil {
 		logger.Error("okta_factor.getOktaFactor", "GetUser", err)
-		if strings.Contains(err.Error(), "Not found") {
+		if isNotFoundError(err) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
-	userProfile := *user.Profile
-	userName := userProfile.Login
+	var userName, userEmail string
+	if user.Profile != nil {
+		userName = user.Profile.GetLogin()
+		userEmail = user.Profile.GetEmail()
+	}
 
 	factorReq := client.UserFactorAPI.GetFactor(ctx, userId, factorId)
 	result, _, err := factorReq.Execute()
 	if err != nil {
 		logger.Error("okta_factor.getOktaFactor", "api_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	if result.GetActualInstance() == nil {
@@ -201,11 +164,18 @@ func getOktaFactor(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 	}
 	f := getFactorDetails(result.GetActualInstance())
 
-	return &UserFactorInfo{UserId: userId, UserName: *userName, Factor: f}, nil
+	return &UserFactorInfo{UserId: userId, UserName: userName, UserEmail: userEmail, Factor: f}, nil
 }
 
 //// UTILITY FUNCTION
 
+// isInvalidFactorError returns true if the factor ID is malformed, which Okta
+// reports as a 400 validation error rather than a 404.
+func isInvalidFactorError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && e.StatusCode == 400 && e.ErrorCode == "E0000001"
+}
+
 func getFactorDetails(i interface{}) OktaFactor {
 	f := OktaFactor{}
 
@@ -0,0 +1,157 @@
+package okta
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOktaFactorList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_factor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaFactorListByUser(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_factor",
+		Quals: map[string]interface{}{"user_id": "00u3carolxxxxxxxxxx3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// The user is fetched directly rather than listing every user
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 0 {
+		t.Errorf("expected the users not to be listed, got %v", c.server.Requests())
+	}
+	if n := c.server.countRequests("GET /api/v1/users/00u3carolxxxxxxxxxx3"); n != 2 {
+		t.Errorf("expected the user and its factors to be fetched, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaFactorListByUserName(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_factor",
+		Columns: []string{"id", "user_id", "user_name", "user_email", "factor_type"},
+		Quals:   map[string]interface{}{"user_name": "alice.smith@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// The users are searched by login, and only the factors of the match are listed
+	if n := c.server.countRequests("GET /api/v1/users?filter=profile.login+eq+"); n != 1 {
+		t.Errorf("expected the users to be searched by login, got %v", c.server.Requests())
+	}
+	if n := c.server.countRequests("GET /api/v1/users/00u2bobxxxxxxxxxxxx2"); n != 0 {
+		t.Errorf("expected only the factors of the matching user to be listed, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaFactorListByUserNameEscaped(t *testing.T) {
+	c := newTestConnection(t)
+
+	_, err := c.query(t, testQuery{
+		Table:   "okta_factor",
+		Columns: []string{"id", "user_id"},
+		Quals:   map[string]interface{}{"user_name": `alice" or profile.login sw "`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The quotes of the login can't end the string literal of the filter
+	filter := url.QueryEscape(`profile.login eq "alice\" or profile.login sw \""`)
+	if n := c.server.countRequests("GET /api/v1/users?filter=" + filter); n != 1 {
+		t.Errorf("expected the login to be escaped in the filter, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaFactorListParallel(t *testing.T) {
+	c := newTestConnection(t, "user_fanout_concurrency = 1")
+
+	rows, err := c.query(t, testQuery{Table: "okta_factor", Columns: []string{"id", "user_id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Errorf("expected 4 factors, got %d", len(rows))
+	}
+	// The users are listed once, and the factors of every user once, in 2 pages for Alice's 3 factors
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 2 {
+		t.Errorf("expected the users to be listed in 2 pages, got %v", c.server.Requests())
+	}
+	for userId, pages := range map[string]int{"00u1alicexxxxxxxxxx1": 2, "00u2bobxxxxxxxxxxxx2": 1, "00u3carolxxxxxxxxxx3": 1} {
+		if n := c.server.countRequests("GET /api/v1/users/" + userId + "/factors"); n != pages {
+			t.Errorf("expected the factors of %s to be listed in %d pages, got %d requests", userId, pages, n)
+		}
+	}
+}
+
+func TestOktaFactorGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_factor",
+		Quals: map[string]interface{}{"user_id": "00u1alicexxxxxxxxxx1", "id": "uft1alicetotpxxxxxx1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaFactorGetNotFound(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_factor",
+		Quals: map[string]interface{}{"user_id": "00u1alicexxxxxxxxxx1", "id": "uft9missingxxxxxxxx9"},
+	})
+	if err != nil {
+		t.Fatalf("expected the 404 to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
+
+func TestOktaFactorGetInvalid(t *testing.T) {
+	c := newTestConnection(t)
+	c.server.fail("/api/v1/users/00u1alicexxxxxxxxxx1/factors/invalid", 400, "E0000001")
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_factor",
+		Quals: map[string]interface{}{"user_id": "00u1alicexxxxxxxxxx1", "id": "invalid"},
+	})
+	if err != nil {
+		t.Fatalf("expected the invalid factor ID to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
+
+func TestOktaFactorGetForbidden(t *testing.T) {
+	c := newTestConnection(t)
+	c.server.fail("/api/v1/users/00u1alicexxxxxxxxxx1/factors/uft1alicetotpxxxxxx1", 403, "E0000006")
+
+	_, err := c.query(t, testQuery{
+		Table: "okta_factor",
+		Quals: map[string]interface{}{"user_id": "00u1alicexxxxxxxxxx1", "id": "uft1alicetotpxxxxxx1"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "E0000006") {
+		t.Errorf("expected the 403 to be returned, got %v", err)
+	}
+}
@@ -20,9 +20,9 @@ func tableOktaGroup() *plugin.Table {
 		Name:        "okta_group",
 		Description: "A Group is made up of users. Groups are useful for representing roles, relationships, and can even be used for subscription tiers.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaGroup,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaGroup,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaGroups,
@@ -33,6 +33,7 @@ func tableOktaGroup() *plugin.Table {
 				{Name: "filter", Require: plugin.Optional},
 				{Name: "last_updated", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
 				{Name: "last_membership_updated", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
+				{Name: "incremental", Require: plugin.Optional},
 			},
 		},
 		HydrateConfig: []plugin.HydrateConfig{
@@ -51,6 +52,7 @@ func tableOktaGroup() *plugin.Table {
 			// Other Columns
 			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Filter string to [filter](https://developer.okta.com/docs/reference/api/users/#list-users-with-a-filter) users. Input filter query should not be encoded."},
 			{Name: "last_membership_updated", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when Group's memberships were last updated."},
+			{Name: "incremental", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("incremental"), Description: "If true, only the groups whose profile or memberships were updated since the last incremental sync of the connection are returned."},
 			{Name: "last_updated", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when Group's profile was last updated."},
 			{Name: "type", Type: proto.ColumnType_STRING, Description: "Determines how a Group's Profile and memberships are managed. Can be one of OKTA_GROUP, APP_GROUP or BUILT_IN."},
 
@@ -83,12 +85,7 @@ func listOktaGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateD
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
 	equalQuals := d.EqualsQuals
 	quals := d.Quals
@@ -115,40 +112,82 @@ func listOktaGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateD
 		input.Filter = strings.Join(filter, " and ")
 	}
 
-	groups, resp, err := client.Group.ListGroups(ctx, &input)
+	if isIncrementalSync(d) {
+		return listOktaGroupsIncremental(ctx, d, client, input)
+	}
+
+	paginator := newPaginatorV2(d, "listOktaGroups", func() ([]*okta.Group, *okta.Response, error) {
+		return client.Group.ListGroups(ctx, &input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listOktaGroupsIncremental lists the groups whose profile or memberships were
+// updated since the last incremental sync, and saves the sync state once every
+// changed group has been listed.
+func listOktaGroupsIncremental(ctx context.Context, d *plugin.QueryData, client *okta.Client, input query.Params) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	state, err := loadSyncState(d)
 	if err != nil {
-		logger.Error("listOktaGroups", "list_groups_error", err)
+		logger.Error("listOktaGroupsIncremental", "load_sync_state_error", err)
 		return nil, err
 	}
 
-	for _, group := range groups {
+	// The state can only be advanced if the listing isn't narrowed down by other quals
+	advanceState := input.Filter == "" && d.QueryContext.Limit == nil
+
+	var syncFilters []string
+	if state.LastUpdated != nil {
+		syncFilters = append(syncFilters, fmt.Sprintf("lastUpdated ge \"%s\"", syncSince(state.LastUpdated)))
+	}
+	if state.LastMembershipUpdated != nil {
+		syncFilters = append(syncFilters, fmt.Sprintf("lastMembershipUpdated ge \"%s\"", syncSince(state.LastMembershipUpdated)))
+	}
+	if len(syncFilters) > 0 {
+		syncFilter := strings.Join(syncFilters, " or ")
+		if input.Filter != "" {
+			input.Filter = fmt.Sprintf("(%s) and (%s)", input.Filter, syncFilter)
+		} else {
+			input.Filter = syncFilter
+		}
+	}
+
+	listing := newSyncListing(state)
+	complete := true
+	paginator := newPaginatorV2(d, "listOktaGroupsIncremental", func() ([]*okta.Group, *okta.Response, error) {
+		return client.Group.ListGroups(ctx, &input)
+	})
+	err = paginator.ForEach(ctx, func(group *okta.Group) bool {
+		// Groups in the skew window of the previous sync may already have been returned by it
+		if listing.seen(group.Id, group.LastUpdated, group.LastMembershipUpdated) {
+			return true
+		}
 		d.StreamListItem(ctx, group)
 
 		// Context can be cancelled due to manual cancellation or the limit has been hit
 		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
+			complete = false
+			return false
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// paging
-	for resp.HasNextPage() {
-		var nextGroupSet []*okta.Group
-		resp, err = resp.Next(ctx, &nextGroupSet)
-		if err != nil {
-			logger.Error("listOktaGroups", "list_groups_paging_error", err)
+	if advanceState && complete && ctx.Err() == nil {
+		if err := saveSyncState(d, listing.next); err != nil {
+			logger.Error("listOktaGroupsIncremental", "save_sync_state_error", err)
 			return nil, err
 		}
-		for _, group := range nextGroupSet {
-			d.StreamListItem(ctx, group)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
 	}
 
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -174,10 +213,11 @@ func getOktaGroup(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDat
 		return nil, err
 	}
 
-	group, _, err := client.Group.GetGroup(ctx, groupId)
+	group, resp, err := client.Group.GetGroup(ctx, groupId)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaGroup", "get_group_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return group, nil
@@ -200,21 +240,19 @@ func listGroupMembers(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 		return nil, err
 	}
 
-	groupMembers, resp, err := client.Group.ListGroupUsers(ctx, groupId, &query.Params{})
-	if err != nil {
-		logger.Error("listGroupMembers", "list_group_users_error", err)
-		return nil, err
+	// Default maximum limit set as per documentation, the column always
+	// contains every member so the query limit doesn't apply
+	// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/listGroupUsers
+	input := query.Params{
+		Limit: 1000,
 	}
 
-	// paging
-	for resp.HasNextPage() {
-		var nextgroupMembersSet []*okta.User
-		resp, err = resp.Next(ctx, &groupMembers)
-		if err != nil {
-			logger.Error("listOktaGroups", "list_group_users_paging_error", err)
-			return nil, err
-		}
-		groupMembers = append(groupMembers, nextgroupMembersSet...)
+	paginator := newPaginatorV2(d, "listGroupMembers", func() ([]*okta.User, *okta.Response, error) {
+		return client.Group.ListGroupUsers(ctx, groupId, &input)
+	})
+	groupMembers, err := paginator.All(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	return groupMembers, nil
@@ -20,7 +20,7 @@ func tableOktaGroupOwner() *plugin.Table {
 		Description: "An Okta Group owner is a designated individual responsible for managing and overseeing a specific group within the Okta identity and access management platform.",
 		List: &plugin.ListConfig{
 			Hydrate:       listOktaGroupOwners,
-			ParentHydrate: listOktaGroups,
+			ParentHydrate: listCachedOktaGroups,
 			KeyColumns:    plugin.OptionalColumns([]string{"group_id"}),
 		},
 		Columns: commonColumns([]*plugin.Column{
@@ -77,16 +77,15 @@ func listOktaGroupOwners(ctx context.Context, d *plugin.QueryData, h *plugin.Hyd
 		return nil, err
 	}
 
-	groupOwnerReq := client.GroupAPI.ListGroupOwners(ctx, groupId)
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/listGroupOwners
+	// If the requested number of items is less than the paging max limit
+	// set the limit to that instead
+	groupOwnerReq := client.GroupAPI.ListGroupOwners(ctx, groupId).Limit(int32(pageSize(d, 1000)))
 
-	owners, resp, err := groupOwnerReq.Execute()
-	if err != nil {
-		logger.Error("okta_group_owner.listGroupOwners", "api_error", err)
-		return nil, err
-	}
-
-	for _, owner := range owners {
-		d.StreamListItem(ctx, GroupOwner{
+	paginator := newPaginatorV4(d, "okta_group_owner.listGroupOwners", groupOwnerReq.Execute)
+	err = paginator.StreamFunc(ctx, func(owner oktav4.GroupOwner) interface{} {
+		return GroupOwner{
 			GroupId:              &groupId,
 			DisplayName:          owner.DisplayName,
 			Id:                   owner.Id,
@@ -96,40 +95,10 @@ func listOktaGroupOwners(ctx context.Context, d *plugin.QueryData, h *plugin.Hyd
 			Resolved:             owner.Resolved,
 			Type:                 owner.Type,
 			AdditionalProperties: owner.AdditionalProperties,
-		})
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextGroupOwners []oktav4.GroupOwner
-		resp, err = resp.Next(&nextGroupOwners)
-		if err != nil {
-			logger.Error("okta_group_owner.listGroupOwners", "api_paging_error", err)
-			return nil, err
-		}
-		for _, owner := range nextGroupOwners {
-			d.StreamListItem(ctx, GroupOwner{
-				GroupId:              &groupId,
-				DisplayName:          owner.DisplayName,
-				Id:                   owner.Id,
-				LastUpdated:          owner.LastUpdated,
-				OriginId:             owner.OriginId,
-				OriginType:           owner.OriginType,
-				Resolved:             owner.Resolved,
-				Type:                 owner.Type,
-				AdditionalProperties: owner.AdditionalProperties,
-			})
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return nil, nil
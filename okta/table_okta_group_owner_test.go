@@ -0,0 +1,25 @@
+package okta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOktaGroupOwnerListLimit(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_group_owner", Columns: []string{"group_id", "id"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["group_id"] != "00g3adminsxxxxxxxxx3" {
+		t.Errorf("expected the owner of the admins group, got %v", rows)
+	}
+
+	// The limit applies to the owners, so it isn't the page size of the groups
+	for _, request := range c.server.Requests() {
+		if strings.HasPrefix(request, "GET /api/v1/groups?") && !strings.Contains(request, "limit=10000") {
+			t.Errorf("expected the groups to be listed with the maximum page size, got %s", request)
+		}
+	}
+}
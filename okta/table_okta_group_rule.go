@@ -2,6 +2,7 @@ package okta
 
 import (
 	"context"
+	"slices"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/okta/okta-sdk-golang/v2/okta/query"
@@ -15,15 +16,15 @@ func tableOktaGroupRule() *plugin.Table {
 		Name:        "okta_group_rule",
 		Description: "Retrieve group rules for Okta. Group rules define conditions and actions for automating group membership.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaGroupRule,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaGroupRule,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
-			Hydrate: listOktaGroupRules,
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      listOktaGroupRules,
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
-		Columns: commonColumns( []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			// Basic columns
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "Unique identifier of the group rule."},
 			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the group rule."},
@@ -31,9 +32,22 @@ func tableOktaGroupRule() *plugin.Table {
 			{Name: "created", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when the group rule was created."},
 			{Name: "last_updated", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when the group rule was last updated."},
 
+			{Name: "expression_type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Conditions.Expression.Type"), Description: "Type of the expression of the rule, e.g. urn:okta:expression:1.0."},
+			{Name: "expression", Type: proto.ColumnType_STRING, Transform: transform.FromField("Conditions.Expression.Value"), Description: "Okta Expression Language expression a user must match to be assigned to the target groups."},
+			{Name: "expression_parse_error", Type: proto.ColumnType_STRING, Hydrate: getOktaGroupRuleExpression, Transform: transform.FromField("ParseError"), Description: "Why the expression couldn't be parsed, if it couldn't."},
+
 			// JSON columns
 			{Name: "conditions", Type: proto.ColumnType_JSON, Description: "Conditions that trigger this group rule."},
 			{Name: "actions", Type: proto.ColumnType_JSON, Description: "Actions performed when the rule conditions are met."},
+			{Name: "expression_ast", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleExpression, Transform: transform.FromField("Ast"), Description: "Syntax tree of the expression, with a type of literal, attribute, call, binary, unary, list or conditional per node."},
+			{Name: "referenced_attributes", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleExpression, Transform: transform.FromField("ReferencedAttributes"), Description: "User attributes referenced by the expression, e.g. user.department."},
+			{Name: "referenced_group_ids", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleExpression, Transform: transform.FromField("ReferencedGroupIds"), Description: "IDs of the groups referenced by the expression, e.g. by isMemberOfAnyGroup."},
+			{Name: "referenced_group_names", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleExpression, Transform: transform.FromField("ReferencedGroupNames"), Description: "Group names or name patterns referenced by the expression, e.g. by isMemberOfGroupNameStartsWith."},
+			{Name: "target_group_ids", Type: proto.ColumnType_JSON, Transform: transform.FromField("Actions.AssignUserToGroups.GroupIds"), Description: "IDs of the groups the rule assigns matching users to."},
+			{Name: "target_group_names", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleGroups, Transform: transform.FromField("TargetGroupNames"), Description: "Names of the groups the rule assigns matching users to."},
+			{Name: "missing_group_ids", Type: proto.ColumnType_JSON, Hydrate: getOktaGroupRuleGroups, Transform: transform.FromField("MissingGroupIds"), Description: "IDs of the target or referenced groups that don't exist, e.g. because they were deleted."},
+			{Name: "excluded_user_ids", Type: proto.ColumnType_JSON, Transform: transform.FromField("Conditions.People.Users.Exclude"), Description: "IDs of the users excluded from the rule."},
+			{Name: "excluded_group_ids", Type: proto.ColumnType_JSON, Transform: transform.FromField("Conditions.People.Groups.Exclude"), Description: "IDs of the groups whose members are excluded from the rule."},
 
 			// Steampipe-specific
 			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Name"), Description: "Title of the group rule."},
@@ -59,48 +73,16 @@ func listOktaGroupRules(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
-	// Fetch group rules
-	groupRules, resp, err := client.Group.ListGroupRules(ctx, &input)
-	if err != nil {
-		logger.Error("okta_group_rule.listOktaGroupRules", "api_error", err)
+	paginator := newPaginatorV2(d, "okta_group_rule.listOktaGroupRules", func() ([]*okta.GroupRule, *okta.Response, error) {
+		return client.Group.ListGroupRules(ctx, &input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
 		return nil, err
 	}
 
-	for _, rule := range groupRules {
-		d.StreamListItem(ctx, rule)
-
-		// Exit if the context is canceled
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextGroupRuleSet []*okta.GroupRule
-		resp, err = resp.Next(ctx, &nextGroupRuleSet)
-		if err != nil {
-			logger.Error("okta_group_rule.listOktaGroupRules", "api_paging_error", err)
-			return nil, err
-		}
-		for _, group_rule := range nextGroupRuleSet {
-			d.StreamListItem(ctx, group_rule)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTION
@@ -127,11 +109,90 @@ func getOktaGroupRule(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 	}
 
 	// Fetch the group rule by ID
-	groupRule, _, err := client.Group.GetGroupRule(ctx, ruleId, nil)
+	groupRule, resp, err := client.Group.GetGroupRule(ctx, ruleId, nil)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("okta_group_rule.getOktaGroupRule", "api_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return groupRule, nil
 }
+
+// GroupRuleExpression is the parsed expression of a group rule.
+type GroupRuleExpression struct {
+	Ast                  *ExpressionNode
+	ParseError           *string
+	ReferencedAttributes []string
+	ReferencedGroupIds   []string
+	ReferencedGroupNames []string
+}
+
+func getOktaGroupRuleExpression(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	groupRule := h.Item.(*okta.GroupRule)
+	if groupRule.Conditions == nil || groupRule.Conditions.Expression == nil || groupRule.Conditions.Expression.Value == "" {
+		return nil, nil
+	}
+
+	ast, err := parseExpression(groupRule.Conditions.Expression.Value)
+	if err != nil {
+		plugin.Logger(ctx).Warn("okta_group_rule.getOktaGroupRuleExpression", "rule_id", groupRule.Id, "parse_error", err)
+		parseError := err.Error()
+		return GroupRuleExpression{ParseError: &parseError}, nil
+	}
+
+	return GroupRuleExpression{
+		Ast:                  ast,
+		ReferencedAttributes: ast.attributes(),
+		ReferencedGroupIds:   ast.functionStringArgs(groupIdFunctions),
+		ReferencedGroupNames: ast.functionStringArgs(groupNameFunctions),
+	}, nil
+}
+
+// GroupRuleGroups are the target groups of a group rule, resolved from the
+// list cache.
+type GroupRuleGroups struct {
+	TargetGroupNames []string
+	MissingGroupIds  []string
+}
+
+func getOktaGroupRuleGroups(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	groupRule := h.Item.(*okta.GroupRule)
+
+	groups, err := getCachedOktaGroups(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("okta_group_rule.getOktaGroupRuleGroups", "list_groups_error", err)
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, group := range groups {
+		if group.Profile != nil {
+			names[group.Id] = group.Profile.Name
+		}
+	}
+
+	result := GroupRuleGroups{TargetGroupNames: []string{}, MissingGroupIds: []string{}}
+	var targetGroupIds []string
+	if groupRule.Actions != nil && groupRule.Actions.AssignUserToGroups != nil {
+		targetGroupIds = groupRule.Actions.AssignUserToGroups.GroupIds
+	}
+	for _, id := range targetGroupIds {
+		if name, ok := names[id]; ok {
+			result.TargetGroupNames = append(result.TargetGroupNames, name)
+		}
+	}
+
+	var referencedGroupIds []string
+	if groupRule.Conditions != nil && groupRule.Conditions.Expression != nil {
+		if ast, err := parseExpression(groupRule.Conditions.Expression.Value); err == nil {
+			referencedGroupIds = ast.functionStringArgs(groupIdFunctions)
+		}
+	}
+	for _, id := range slices.Concat(targetGroupIds, referencedGroupIds) {
+		if _, ok := names[id]; !ok && !slices.Contains(result.MissingGroupIds, id) {
+			result.MissingGroupIds = append(result.MissingGroupIds, id)
+		}
+	}
+
+	return result, nil
+}
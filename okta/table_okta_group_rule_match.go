@@ -0,0 +1,219 @@
+package okta
+
+import (
+	"context"
+	"slices"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOktaGroupRuleMatch() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_group_rule_match",
+		Description: "Evaluates every group rule against every Okta user offline, to preview which users each rule would assign and compare it with the actual group memberships.",
+		List: &plugin.ListConfig{
+			Hydrate: listOktaGroupRuleMatches,
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "rule_id", Require: plugin.Optional},
+				{Name: "user_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "rule_id", Type: proto.ColumnType_STRING, Description: "ID of the group rule."},
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "ID of the user."},
+			{Name: "would_assign", Type: proto.ColumnType_BOOL, Description: "True if the rule would assign the user to its target groups. Null if the expression can't be evaluated offline."},
+			{Name: "is_member", Type: proto.ColumnType_BOOL, Description: "True if the user is a member of every target group of the rule."},
+
+			// Other Columns
+			{Name: "rule_name", Type: proto.ColumnType_STRING, Description: "Name of the group rule."},
+			{Name: "rule_status", Type: proto.ColumnType_STRING, Description: "Status of the group rule, e.g. ACTIVE. Only active rules assign users."},
+			{Name: "user_name", Type: proto.ColumnType_STRING, Description: "Login of the user."},
+			{Name: "user_status", Type: proto.ColumnType_STRING, Description: "Current status of the user."},
+			{Name: "excluded", Type: proto.ColumnType_BOOL, Description: "True if the user, or one of their groups, is excluded from the rule."},
+			{Name: "evaluation_error", Type: proto.ColumnType_STRING, Description: "Why the expression couldn't be evaluated offline, e.g. an unsupported function."},
+
+			// JSON Columns
+			{Name: "target_group_ids", Type: proto.ColumnType_JSON, Description: "IDs of the groups the rule assigns matching users to."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("RuleName"), Description: titleDescription},
+		}),
+	}
+}
+
+type GroupRuleMatch struct {
+	RuleId          string
+	RuleName        string
+	RuleStatus      string
+	UserId          string
+	UserName        string
+	UserStatus      string
+	WouldAssign     *bool
+	IsMember        *bool
+	Excluded        bool
+	EvaluationError *string
+	TargetGroupIds  []string
+}
+
+// parsedGroupRule is a group rule with its expression parsed once for every
+// user.
+type parsedGroupRule struct {
+	rule       *okta.GroupRule
+	ast        *ExpressionNode
+	parseError error
+}
+
+//// LIST FUNCTION
+
+func listOktaGroupRuleMatches(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	rules, err := listGroupRulesMatchingQuals(ctx, d)
+	if err != nil {
+		logger.Error("okta_group_rule_match.listOktaGroupRuleMatches", "list_group_rules_error", err)
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	parsedRules := make([]parsedGroupRule, len(rules))
+	for i, rule := range rules {
+		parsedRules[i].rule = rule
+		if rule.Conditions != nil && rule.Conditions.Expression != nil {
+			parsedRules[i].ast, parsedRules[i].parseError = parseExpression(rule.Conditions.Expression.Value)
+		}
+	}
+
+	users, err := listUsersMatchingQuals(ctx, d)
+	if err != nil {
+		logger.Error("okta_group_rule_match.listOktaGroupRuleMatches", "list_users_error", err)
+		return nil, err
+	}
+
+	listUserGroups, err := newUserGroupsLookup(ctx, d, len(users))
+	if err != nil {
+		logger.Error("okta_group_rule_match.listOktaGroupRuleMatches", "list_user_groups_error", err)
+		return nil, err
+	}
+
+	err = forEachUserConcurrently(ctx, d, "okta_group_rule_match.listOktaGroupRuleMatches", users, func(user *okta.User) error {
+		groups, err := listUserGroups(user.Id)
+		if err != nil {
+			return err
+		}
+		for _, rule := range parsedRules {
+			d.StreamListItem(ctx, groupRuleMatch(rule, user, groups))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listGroupRulesMatchingQuals returns the group rule matching the rule_id
+// qual, or every group rule of the org.
+func listGroupRulesMatchingQuals(ctx context.Context, d *plugin.QueryData) ([]*okta.GroupRule, error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if ruleId := d.EqualsQualString("rule_id"); ruleId != "" {
+		rule, resp, err := client.Group.GetGroupRule(ctx, ruleId, nil)
+		err = withResponseStatus(err, resp)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []*okta.GroupRule{rule}, nil
+	}
+
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/reference/api/groups/#list-group-rules
+	paginator := newPaginatorV2(d, "okta_group_rule_match.listGroupRules", func() ([]*okta.GroupRule, *okta.Response, error) {
+		return client.Group.ListGroupRules(ctx, &query.Params{Limit: 200})
+	})
+	return paginator.All(ctx)
+}
+
+//// UTILITY FUNCTIONS
+
+func groupRuleMatch(parsed parsedGroupRule, user *okta.User, groups []*okta.Group) GroupRuleMatch {
+	rule := parsed.rule
+	userName, _ := userLoginAndEmail(user)
+	match := GroupRuleMatch{
+		RuleId:     rule.Id,
+		RuleName:   rule.Name,
+		RuleStatus: rule.Status,
+		UserId:     user.Id,
+		UserName:   userName,
+		UserStatus: user.Status,
+	}
+
+	groupIds := make([]string, len(groups))
+	for i, group := range groups {
+		groupIds[i] = group.Id
+	}
+
+	if rule.Actions != nil && rule.Actions.AssignUserToGroups != nil {
+		match.TargetGroupIds = rule.Actions.AssignUserToGroups.GroupIds
+		isMember := len(match.TargetGroupIds) > 0
+		for _, id := range match.TargetGroupIds {
+			if !slices.Contains(groupIds, id) {
+				isMember = false
+			}
+		}
+		match.IsMember = &isMember
+	}
+
+	if rule.Conditions != nil && rule.Conditions.People != nil {
+		people := rule.Conditions.People
+		if people.Users != nil && slices.Contains(people.Users.Exclude, user.Id) {
+			match.Excluded = true
+		}
+		if people.Groups != nil && slices.ContainsFunc(people.Groups.Exclude, func(id string) bool { return slices.Contains(groupIds, id) }) {
+			match.Excluded = true
+		}
+	}
+
+	var wouldAssign bool
+	switch {
+	case match.Excluded:
+		wouldAssign = false
+	case parsed.parseError != nil:
+		evaluationError := parsed.parseError.Error()
+		match.EvaluationError = &evaluationError
+		return match
+	case parsed.ast == nil:
+		// Rules without an expression match every user
+		wouldAssign = true
+	default:
+		var profile map[string]interface{}
+		if user.Profile != nil {
+			profile = *user.Profile
+		}
+		matches, err := evaluateGroupRuleExpression(parsed.ast, &expressionEnv{profile: profile, groups: groups})
+		if err != nil {
+			evaluationError := err.Error()
+			match.EvaluationError = &evaluationError
+			return match
+		}
+		wouldAssign = matches
+	}
+	match.WouldAssign = &wouldAssign
+
+	return match
+}
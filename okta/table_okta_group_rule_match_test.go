@@ -0,0 +1,95 @@
+package okta
+
+import (
+	"testing"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+)
+
+func TestOktaGroupRuleMatchList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_group_rule_match"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaGroupRuleMatchListByRuleAndUser(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_group_rule_match",
+		Columns: []string{"rule_id", "user_id", "would_assign", "is_member"},
+		Quals:   map[string]interface{}{"rule_id": "0pr3managedxxxxxxxx3", "user_id": "00u3carolxxxxxxxxxx3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	if n := c.server.countRequests("GET /api/v1/groups/rules?"); n != 0 {
+		t.Errorf("expected the group rules not to be listed, got %v", c.server.Requests())
+	}
+}
+
+func TestEvaluateGroupRuleExpression(t *testing.T) {
+	env := &expressionEnv{
+		profile: map[string]interface{}{
+			"department": "Engineering",
+			"login":      "Alice@example.com",
+			"level":      float64(3),
+			"roles":      []interface{}{"dev", "ops"},
+		},
+		groups: []*okta.Group{
+			{Id: "00g1", Profile: &okta.GroupProfile{Name: "Engineering EU"}},
+		},
+	}
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`user.department == "Engineering"`, true},
+		{`user.department != "Engineering"`, false},
+		{`user.missing == null`, true},
+		{`user.missing == "x"`, false},
+		{`user.level >= 3 and user.level < 4`, true},
+		{`String.startsWith(user.login.toLowerCase(), "alice")`, true},
+		{`String.stringContains(user.login, "@example.com") && !String.startsWith(user.login, "bob")`, true},
+		{`String.substringAfter(user.login, "@") == "example.com"`, true},
+		{`Arrays.contains(user.roles, "ops") OR Arrays.contains({"a"}, "b")`, true},
+		{`Arrays.contains({"1002", "1003"}, user.department)`, false},
+		{`isMemberOfAnyGroup("00g9", "00g1")`, true},
+		{`isMemberOfGroup("00g9")`, false},
+		{`isMemberOfGroupNameStartsWith("Engineering")`, true},
+		{`isMemberOfGroupNameRegex("Eng.*EU")`, true},
+		{`isMemberOfGroupNameRegex("Eng")`, false},
+		{`user.level > 5 ? false : true`, true},
+	}
+	for _, test := range tests {
+		node, err := parseExpression(test.expression)
+		if err != nil {
+			t.Errorf("parseExpression(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		got, err := evaluateGroupRuleExpression(node, env)
+		if err != nil {
+			t.Errorf("evaluateGroupRuleExpression(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("evaluateGroupRuleExpression(%q) = %v, want %v", test.expression, got, test.want)
+		}
+	}
+
+	for _, expression := range []string{`app.name == "x"`, `Convert.toInt(user.level) == 3`, `user.department`, `user.login.matches("a")`} {
+		node, err := parseExpression(expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := evaluateGroupRuleExpression(node, env); err == nil {
+			t.Errorf("evaluateGroupRuleExpression(%q) expected an error", expression)
+		}
+	}
+}
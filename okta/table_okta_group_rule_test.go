@@ -0,0 +1,63 @@
+package okta
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOktaGroupRuleList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_group_rule"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{
+			`user.department == "Engineering"`,
+			`{"type":"binary","operator":"==","args":[{"type":"attribute","attribute":"user.department"},{"type":"literal","value":"Engineering"}]}`,
+		},
+		{
+			`NOT (user.a eq 'it''s') OR user.b AND user.c`,
+			`{"type":"binary","operator":"||","args":[{"type":"unary","operator":"!","args":[{"type":"binary","operator":"==","args":[{"type":"attribute","attribute":"user.a"},{"type":"literal","value":"it's"}]}]},{"type":"binary","operator":"&&","args":[{"type":"attribute","attribute":"user.b"},{"type":"attribute","attribute":"user.c"}]}]}`,
+		},
+		{
+			`String.startsWith(user.login.toLowerCase(), "a")`,
+			`{"type":"call","function":"String.startsWith","args":[{"type":"call","function":"toLowerCase","receiver":{"type":"attribute","attribute":"user.login"}},{"type":"literal","value":"a"}]}`,
+		},
+		{
+			`Arrays.contains({"a", 1}, user.x) ? true : null`,
+			`{"type":"conditional","args":[{"type":"call","function":"Arrays.contains","args":[{"type":"list","args":[{"type":"literal","value":"a"},{"type":"literal","value":1}]},{"type":"attribute","attribute":"user.x"}]},{"type":"literal","value":true},{"type":"literal"}]}`,
+		},
+	}
+	for _, test := range tests {
+		node, err := parseExpression(test.expression)
+		if err != nil {
+			t.Errorf("parseExpression(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		var got strings.Builder
+		encoder := json.NewEncoder(&got)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(node); err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(got.String()) != test.want {
+			t.Errorf("parseExpression(%q) = %s, want %s", test.expression, got.String(), test.want)
+		}
+	}
+
+	for _, expression := range []string{"", "user.a ==", `"unterminated`, "f(a,", "(a", "a b", "a # b"} {
+		if _, err := parseExpression(expression); err == nil {
+			t.Errorf("parseExpression(%q) expected an error", expression)
+		}
+	}
+}
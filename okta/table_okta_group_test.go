@@ -0,0 +1,43 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaGroupList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_group"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaGroupGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_group",
+		Quals: map[string]interface{}{"id": "00g2engineeringxxxx2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaGroupGetNotFound(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_group",
+		Quals: map[string]interface{}{"id": "00g9missingxxxxxxxx9"},
+	})
+	if err != nil {
+		t.Fatalf("expected the 404 to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
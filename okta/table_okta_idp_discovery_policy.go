@@ -56,35 +56,16 @@ func listOktaIdpDiscoveryPolicies(ctx context.Context, d *plugin.QueryData, _ *p
 	if d.Table.Name == "okta_idp_discovery_policy" {
 		input.Type = "IDP_DISCOVERY"
 	}
-	policies, resp, err := client.Policy.ListPolicies(ctx, input)
-	if err != nil {
-		logger.Error("listOktaIdpDiscoveryPolicies", "list_policies_error", err)
-		return nil, err
-	}
-	for _, policy := range policies {
-		d.StreamListItem(ctx, policy)
+	// The policies API has no documented maximum page size, so a page size is
+	// only requested when the query has a limit
+	input.Limit = pageSize(d, 0)
 
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
+	paginator := newPaginatorV2(d, "listOktaIdpDiscoveryPolicies", func() ([]*okta.AuthorizationServerPolicy, *okta.Response, error) {
+		return client.Policy.ListPolicies(ctx, input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		return nil, err
 	}
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicySet []*okta.AuthorizationServerPolicy
-		resp, err = resp.Next(ctx, &nextPolicySet)
-		if err != nil {
-			logger.Error("listOktaIdpDiscoveryPolicies", "list_policies_paging_error", err)
-			return nil, err
-		}
-		for _, policy := range nextPolicySet {
-			d.StreamListItem(ctx, policy)
 
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-	return nil, err
+	return nil, nil
 }
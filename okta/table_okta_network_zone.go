@@ -2,6 +2,10 @@ package okta
 
 import (
 	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
 
 	"github.com/okta/okta-sdk-golang/v5/okta"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
@@ -16,17 +20,22 @@ func tableOktaNetworkZone() *plugin.Table {
 		Name:        "okta_network_zone",
 		Description: "The Okta Zones provides operations to manage Zones in your organization. There are two usage Zone types: Policy Network Zones and Block List Network Zones. Policy Network Zones are used to guide policy decisions. Block List Network Zones are used to deny access from certain IP addresses, locations, proxy types, or Autonomous System Numbers (ASNs) before policy evaluation.",
 		Get: &plugin.GetConfig{
-			KeyColumns: plugin.SingleColumn("id"),
-			Hydrate:    getOktaNetworkZone,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "id", Require: plugin.Required},
+				{Name: "ip_address", Require: plugin.Optional},
+			},
+			Hydrate: getOktaNetworkZone,
 		},
 		List: &plugin.ListConfig{
-			Hydrate: listOktaNetworkZones,
+			Hydrate:    listOktaNetworkZones,
+			KeyColumns: plugin.OptionalColumns([]string{"ip_address"}),
 		},
 		Columns: commonColumns([]*plugin.Column{
 			// Top Columns
 			{Name: "name", Type: proto.ColumnType_STRING, Description: "Unique name for the zone."},
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "Identifier of the network zone."},
 			{Name: "created", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when the network zone was created."},
+			{Name: "zone_kind", Type: proto.ColumnType_STRING, Transform: transform.From(networkZoneCondition), Description: "Kind of the network zone. Can be one of ip, dynamic or enhanced_dynamic."},
 
 			// Other Columns
 			{Name: "last_updated", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when the network zone was last modified."},
@@ -35,12 +44,23 @@ func tableOktaNetworkZone() *plugin.Table {
 			{Name: "system", Type: proto.ColumnType_BOOL, Description: "Indicates if this is a system network zone."},
 			{Name: "type", Type: proto.ColumnType_STRING, Description: "The type of the network zone."},
 			{Name: "usage", Type: proto.ColumnType_STRING, Description: "Usage of Zone: POLICY, BLOCKLIST."},
+			{Name: "ip_address", Type: proto.ColumnType_INET, Hydrate: getOktaNetworkZoneIpAddressMatch, Transform: transform.FromField("IpAddress"), Description: "IP address to find the matching zones of. Only set when specified in the where clause."},
+			{Name: "ip_address_match", Type: proto.ColumnType_STRING, Hydrate: getOktaNetworkZoneIpAddressMatch, Transform: transform.FromField("Match"), Description: "How the zone matches the ip_address. Can be one of gateway, proxy or server_side, for dynamic zones whose ASNs, locations and proxy types can only be matched by Okta."},
 
 			// JSON Columns
 			{Name: "asns", Type: proto.ColumnType_JSON, Description: "Format of each array value: a string representation of an ASN numeric value."},
 			{Name: "gateways", Type: proto.ColumnType_JSON, Description: "IP addresses (range or CIDR form) of the zone."},
 			{Name: "locations", Type: proto.ColumnType_JSON, Description: "The geolocations of the zone."},
 			{Name: "proxies", Type: proto.ColumnType_JSON, Description: "IP addresses (range or CIDR form) that are allowed to forward a request from gateway addresses. These proxies are automatically trusted by Threat Insights. These proxies are used to identify the client IP of a request."},
+			{Name: "asns_include", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "ASNs matched by a dynamic or enhanced dynamic zone."},
+			{Name: "asns_exclude", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "ASNs excluded from an enhanced dynamic zone."},
+			{Name: "locations_include", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "Countries and regions matched by a dynamic or enhanced dynamic zone."},
+			{Name: "locations_exclude", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "Countries and regions excluded from an enhanced dynamic zone."},
+			{Name: "countries_include", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "ISO 3166-1 country codes of the locations matched by a dynamic or enhanced dynamic zone."},
+			{Name: "countries_exclude", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "ISO 3166-1 country codes of the locations excluded from an enhanced dynamic zone."},
+			{Name: "ip_service_categories_include", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "IP service categories, e.g. ALL_ANONYMIZERS, matched by an enhanced dynamic zone."},
+			{Name: "ip_service_categories_exclude", Type: proto.ColumnType_JSON, Transform: transform.From(networkZoneCondition), Description: "IP service categories excluded from an enhanced dynamic zone."},
+			{Name: "ip_address_server_side_criteria", Type: proto.ColumnType_JSON, Hydrate: getOktaNetworkZoneIpAddressMatch, Transform: transform.FromField("ServerSideCriteria"), Description: "Criteria of a dynamic zone that can only be matched with the ip_address by Okta, e.g. asns, locations or proxy_type."},
 
 			// Steampipe Columns
 			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Name"), Description: titleDescription},
@@ -53,67 +73,45 @@ func tableOktaNetworkZone() *plugin.Table {
 func listOktaNetworkZones(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	logger := plugin.Logger(ctx)
 
-	client, err := ConnectV5(ctx, d)
+	ipAddress, hasIpAddress, err := networkZoneIpAddressQual(d)
 	if err != nil {
-		logger.Error("okta_network_zone.listOktaNetworkZones", "connect_error", err)
 		return nil, err
 	}
 
-	// If the requested number of items is less than the paging max limit
-	// set the limit to that instead
-	limit := int64(200)
-	if d.QueryContext.Limit != nil {
-		if *d.QueryContext.Limit < limit {
-			limit = *d.QueryContext.Limit
-		}
-	}
-
-	// Request
-	zoneReq := client.NetworkZoneAPI.ListNetworkZones(ctx)
-
-	zones, resp, err := zoneReq.Limit(int32(limit)).Execute()
+	client, err := ConnectV5(ctx, d)
 	if err != nil {
-		logger.Error("okta_network_zone.listOktaNetworkZones", "api_error", err)
+		logger.Error("okta_network_zone.listOktaNetworkZones", "connect_error", err)
 		return nil, err
 	}
 
-	for _, zone := range zones {
-		z := processNetworkZones(zone)
-		if z != nil {
-			d.StreamListItem(ctx, z)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextZoneSet []okta.ListNetworkZones200ResponseInner
+	// If the requested number of items is less than the paging max limit
+	// set the limit to that instead
+	limit := pageSize(d, 200)
 
-		resp, err = resp.Next(&nextZoneSet)
+	paginator := newPaginatorV5(d, "okta_network_zone.listOktaNetworkZones", client.NetworkZoneAPI.ListNetworkZones(ctx).Limit(int32(limit)).Execute)
+	if !hasIpAddress {
+		err = paginator.StreamFunc(ctx, processNetworkZones)
 		if err != nil {
-			logger.Error("listOktaNetworkZones", "list_network_zones_paging_error", err)
 			return nil, err
 		}
+		return nil, nil
+	}
 
-		for _, zone := range nextZoneSet {
-			z := processNetworkZones(zone)
-			if z != nil {
-				d.StreamListItem(ctx, z)
-
-				// Context can be cancelled due to manual cancellation or the limit has been hit
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
+	// Zones are matched in Go, as the API can't filter zones by address
+	err = paginator.ForEach(ctx, func(networkZone okta.ListNetworkZones200ResponseInner) bool {
+		zone := processNetworkZones(networkZone)
+		if networkZoneIpAddressMatch(zone, ipAddress) != nil {
+			d.StreamListItem(ctx, zone)
 		}
-
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		logger.Error("okta_network_zone.listOktaNetworkZones", "api_error", err)
+		return nil, err
 	}
 
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTION
@@ -134,14 +132,36 @@ func getOktaNetworkZone(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 	networkZone, _, err := client.NetworkZoneAPI.GetNetworkZone(ctx, id).Execute()
 	if err != nil {
 		logger.Error("getOktaNetworkZone", "get_network_zone_error", err)
+		return nil, handleOktaError(d, err)
+	}
+
+	if networkZone == nil {
+		return nil, nil
+	}
+
+	zone := processNetworkZones(*networkZone)
+	ipAddress, hasIpAddress, err := networkZoneIpAddressQual(d)
+	if err != nil {
 		return nil, err
 	}
+	if hasIpAddress && networkZoneIpAddressMatch(zone, ipAddress) == nil {
+		return nil, nil
+	}
+
+	return zone, nil
+}
 
-	if networkZone != nil {
-		return processNetworkZones(*networkZone), nil
+func getOktaNetworkZoneIpAddressMatch(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ipAddress, hasIpAddress, err := networkZoneIpAddressQual(d)
+	if err != nil || !hasIpAddress {
+		return nil, err
 	}
 
-	return nil, nil
+	match := networkZoneIpAddressMatch(h.Item, ipAddress)
+	if match != nil {
+		match.IpAddress = ipAddress.String()
+	}
+	return match, nil
 }
 
 // Helper function to process and stream network zones
@@ -158,3 +178,162 @@ func processNetworkZones(networkZone okta.ListNetworkZones200ResponseInner) inte
 
 	return nil
 }
+
+// networkZoneCondition returns the typed conditions of the three kinds of
+// zones, named after the column.
+func networkZoneCondition(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	switch zone := d.HydrateItem.(type) {
+	case *okta.IPNetworkZone:
+		if d.ColumnName == "zone_kind" {
+			return "ip", nil
+		}
+	case *okta.DynamicNetworkZone:
+		switch d.ColumnName {
+		case "zone_kind":
+			return "dynamic", nil
+		case "asns_include":
+			return zone.Asns, nil
+		case "locations_include":
+			return zone.Locations, nil
+		case "countries_include":
+			return networkZoneCountries(zone.Locations), nil
+		}
+	case *okta.EnhancedDynamicNetworkZone:
+		switch d.ColumnName {
+		case "zone_kind":
+			return "enhanced_dynamic", nil
+		case "asns_include":
+			if zone.Asns != nil {
+				return zone.Asns.Include, nil
+			}
+		case "asns_exclude":
+			// The SDK doesn't model excluded ASNs
+			if zone.Asns != nil {
+				return zone.Asns.AdditionalProperties["exclude"], nil
+			}
+		case "locations_include":
+			if zone.Locations != nil {
+				return zone.Locations.Include, nil
+			}
+		case "locations_exclude":
+			if zone.Locations != nil {
+				return zone.Locations.Exclude, nil
+			}
+		case "countries_include":
+			if zone.Locations != nil {
+				return networkZoneCountries(zone.Locations.Include), nil
+			}
+		case "countries_exclude":
+			if zone.Locations != nil {
+				return networkZoneCountries(zone.Locations.Exclude), nil
+			}
+		case "ip_service_categories_include":
+			if zone.IpServiceCategories != nil {
+				return zone.IpServiceCategories.Include, nil
+			}
+		case "ip_service_categories_exclude":
+			if zone.IpServiceCategories != nil {
+				return zone.IpServiceCategories.Exclude, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// networkZoneCountries returns the distinct countries of the locations, which
+// may also be regions of a country, e.g. US-CA.
+func networkZoneCountries(locations []okta.NetworkZoneLocation) []string {
+	if len(locations) == 0 {
+		return nil
+	}
+	countries := []string{}
+	for _, location := range locations {
+		if country := location.GetCountry(); country != "" && !slices.Contains(countries, country) {
+			countries = append(countries, country)
+		}
+	}
+	return countries
+}
+
+// NetworkZoneIpAddressMatch is how a zone matches an IP address.
+type NetworkZoneIpAddressMatch struct {
+	IpAddress          string
+	Match              string
+	ServerSideCriteria []string
+}
+
+// networkZoneIpAddressQual returns the ip_address qual, if any.
+func networkZoneIpAddressQual(d *plugin.QueryData) (netip.Addr, bool, error) {
+	qual := d.EqualsQuals["ip_address"]
+	if qual == nil {
+		return netip.Addr{}, false, nil
+	}
+
+	value := qual.GetStringValue()
+	if inet := qual.GetInetValue(); inet != nil {
+		value = inet.GetAddr()
+	}
+	// Postgres may send the address with its mask, e.g. 10.0.0.1/32
+	value, _, _ = strings.Cut(value, "/")
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false, fmt.Errorf("invalid ip_address %q: %v", value, err)
+	}
+	return addr.Unmap(), true, nil
+}
+
+// networkZoneIpAddressMatch returns how the zone matches the IP address, or
+// nil if it doesn't. IP zones match on their gateways and proxies, whereas
+// dynamic zones may match any IP address depending on criteria that only Okta
+// can evaluate.
+func networkZoneIpAddressMatch(zone interface{}, addr netip.Addr) *NetworkZoneIpAddressMatch {
+	switch zone := zone.(type) {
+	case *okta.IPNetworkZone:
+		if networkZoneAddressesContain(zone.Gateways, addr) {
+			return &NetworkZoneIpAddressMatch{Match: "gateway"}
+		}
+		if networkZoneAddressesContain(zone.Proxies, addr) {
+			return &NetworkZoneIpAddressMatch{Match: "proxy"}
+		}
+	case *okta.DynamicNetworkZone:
+		var criteria []string
+		if len(zone.Asns) > 0 {
+			criteria = append(criteria, "asns")
+		}
+		if len(zone.Locations) > 0 {
+			criteria = append(criteria, "locations")
+		}
+		if zone.GetProxyType() != "" {
+			criteria = append(criteria, "proxy_type")
+		}
+		return &NetworkZoneIpAddressMatch{Match: "server_side", ServerSideCriteria: criteria}
+	case *okta.EnhancedDynamicNetworkZone:
+		var criteria []string
+		if asns := zone.Asns; asns != nil && len(asns.Include) > 0 {
+			criteria = append(criteria, "asns")
+		}
+		if locations := zone.Locations; locations != nil && (len(locations.Include) > 0 || len(locations.Exclude) > 0) {
+			criteria = append(criteria, "locations")
+		}
+		if categories := zone.IpServiceCategories; categories != nil && (len(categories.Include) > 0 || len(categories.Exclude) > 0) {
+			criteria = append(criteria, "ip_service_categories")
+		}
+		return &NetworkZoneIpAddressMatch{Match: "server_side", ServerSideCriteria: criteria}
+	}
+	return nil
+}
+
+func networkZoneAddressesContain(addresses []okta.NetworkZoneAddress, addr netip.Addr) bool {
+	for _, address := range addresses {
+		prefixes, err := parseNetworkZoneAddress(address.GetValue())
+		if err != nil {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOktaNetworkZoneAddress() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_network_zone_address",
+		Description: "Represents a gateway or proxy address of an Okta IP network zone, normalized into one row per CIDR.",
+		List: &plugin.ListConfig{
+			Hydrate:    listOktaNetworkZoneAddresses,
+			KeyColumns: plugin.OptionalColumns([]string{"zone_id"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "zone_id", Type: proto.ColumnType_STRING, Description: "Identifier of the network zone."},
+			{Name: "zone_name", Type: proto.ColumnType_STRING, Description: "Name of the network zone."},
+			{Name: "cidr", Type: proto.ColumnType_CIDR, Description: "The address block, in CIDR form. Ranges are split into the minimal list of CIDRs covering them."},
+			{Name: "list_kind", Type: proto.ColumnType_STRING, Description: "List of the zone the address is in. Can be one of gateway or proxy."},
+
+			// Other Columns
+			{Name: "address_family", Type: proto.ColumnType_STRING, Description: "Address family of the address block. Can be one of IPv4 or IPv6."},
+			{Name: "first_address", Type: proto.ColumnType_INET, Description: "First IP address of the address block."},
+			{Name: "last_address", Type: proto.ColumnType_INET, Description: "Last IP address of the address block."},
+			{Name: "entry_type", Type: proto.ColumnType_STRING, Description: "Type of the zone entry the address block was normalized from. Can be one of CIDR or RANGE."},
+			{Name: "entry_value", Type: proto.ColumnType_STRING, Description: "Value of the zone entry the address block was normalized from, e.g. 10.0.0.1-10.0.0.6."},
+			{Name: "zone_status", Type: proto.ColumnType_STRING, Description: "Status of the network zone: ACTIVE or INACTIVE."},
+			{Name: "usage", Type: proto.ColumnType_STRING, Description: "Usage of the network zone: POLICY or BLOCKLIST."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Cidr"), Description: titleDescription},
+		}),
+	}
+}
+
+type NetworkZoneAddress struct {
+	ZoneId        string
+	ZoneName      string
+	ZoneStatus    string
+	Usage         string
+	ListKind      string
+	Cidr          string
+	AddressFamily string
+	FirstAddress  string
+	LastAddress   string
+	EntryType     string
+	EntryValue    string
+}
+
+//// LIST FUNCTION
+
+func listOktaNetworkZoneAddresses(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	client, err := ConnectV5(ctx, d)
+	if err != nil {
+		logger.Error("okta_network_zone_address.listOktaNetworkZoneAddresses", "connect_error", err)
+		return nil, err
+	}
+
+	// Restrict API calls based on the zone_id query parameter
+	if zoneId := d.EqualsQualString("zone_id"); zoneId != "" {
+		networkZone, _, err := client.NetworkZoneAPI.GetNetworkZone(ctx, zoneId).Execute()
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil, nil
+			}
+			logger.Error("okta_network_zone_address.listOktaNetworkZoneAddresses", "get_network_zone_error", err)
+			return nil, handleOktaError(d, err)
+		}
+		if networkZone.IPNetworkZone != nil {
+			streamNetworkZoneAddresses(ctx, d, networkZone.IPNetworkZone)
+		}
+		return nil, nil
+	}
+
+	paginator := newPaginatorV5(d, "okta_network_zone_address.listOktaNetworkZoneAddresses", client.NetworkZoneAPI.ListNetworkZones(ctx).Limit(200).Execute)
+	err = paginator.ForEach(ctx, func(networkZone okta.ListNetworkZones200ResponseInner) bool {
+		// Dynamic zones match on ASNs, locations and proxy types instead
+		if networkZone.IPNetworkZone != nil {
+			streamNetworkZoneAddresses(ctx, d, networkZone.IPNetworkZone)
+		}
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		logger.Error("okta_network_zone_address.listOktaNetworkZoneAddresses", "api_error", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func streamNetworkZoneAddresses(ctx context.Context, d *plugin.QueryData, zone *okta.IPNetworkZone) {
+	logger := plugin.Logger(ctx)
+
+	lists := []struct {
+		kind      string
+		addresses []okta.NetworkZoneAddress
+	}{
+		{"gateway", zone.Gateways},
+		{"proxy", zone.Proxies},
+	}
+	for _, list := range lists {
+		for _, address := range list.addresses {
+			prefixes, err := parseNetworkZoneAddress(address.GetValue())
+			if err != nil {
+				logger.Warn("okta_network_zone_address.streamNetworkZoneAddresses", "zone_id", zone.GetId(), "parse_error", err)
+				continue
+			}
+			for _, prefix := range prefixes {
+				addressFamily := "IPv4"
+				if prefix.Addr().Is6() {
+					addressFamily = "IPv6"
+				}
+				d.StreamListItem(ctx, NetworkZoneAddress{
+					ZoneId:        zone.GetId(),
+					ZoneName:      zone.GetName(),
+					ZoneStatus:    zone.GetStatus(),
+					Usage:         zone.GetUsage(),
+					ListKind:      list.kind,
+					Cidr:          prefix.String(),
+					AddressFamily: addressFamily,
+					FirstAddress:  prefix.Addr().String(),
+					LastAddress:   lastAddressOfPrefix(prefix).String(),
+					EntryType:     address.GetType(),
+					EntryValue:    address.GetValue(),
+				})
+			}
+		}
+	}
+}
+
+//// UTILITY FUNCTIONS
+
+// parseNetworkZoneAddress parses a network zone entry, i.e. a CIDR such as
+// 10.0.0.0/8, a range such as 10.0.0.1-10.0.0.6 or a single IP address, into
+// the minimal list of CIDRs covering it.
+func parseNetworkZoneAddress(value string) ([]netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+
+	if first, last, isRange := strings.Cut(value, "-"); isRange {
+		start, err := netip.ParseAddr(strings.TrimSpace(first))
+		if err != nil {
+			return nil, err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(last))
+		if err != nil {
+			return nil, err
+		}
+		start, end = start.Unmap(), end.Unmap()
+		if start.BitLen() != end.BitLen() || end.Less(start) {
+			return nil, fmt.Errorf("invalid address range %q", value)
+		}
+		return rangeToPrefixes(start, end), nil
+	}
+
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		// Postgres rejects CIDRs with host bits set, e.g. 10.0.0.1/8
+		return []netip.Prefix{prefix.Masked()}, nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return nil, err
+	}
+	addr = addr.Unmap()
+	return []netip.Prefix{netip.PrefixFrom(addr, addr.BitLen())}, nil
+}
+
+// rangeToPrefixes splits an address range into the minimal list of CIDRs
+// covering it, e.g. 10.0.0.1-10.0.0.6 into 10.0.0.1/32, 10.0.0.2/31,
+// 10.0.0.4/31 and 10.0.0.6/32.
+func rangeToPrefixes(start, end netip.Addr) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for {
+		// Widen the block as long as start is its first address and it ends
+		// before the end of the range
+		prefix := netip.PrefixFrom(start, start.BitLen())
+		for bits := start.BitLen() - 1; bits >= 0; bits-- {
+			wider := netip.PrefixFrom(start, bits).Masked()
+			if wider.Addr() != start || end.Less(lastAddressOfPrefix(wider)) {
+				break
+			}
+			prefix = wider
+		}
+		prefixes = append(prefixes, prefix)
+
+		last := lastAddressOfPrefix(prefix)
+		if !last.Less(end) {
+			return prefixes
+		}
+		start = last.Next()
+	}
+}
+
+// lastAddressOfPrefix returns the last address of a CIDR, i.e. its broadcast
+// address for IPv4.
+func lastAddressOfPrefix(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr()
+	bytes := addr.As16()
+	// IPv4 addresses are the last 4 bytes
+	offset := 128 - addr.BitLen()
+	for bit := offset + prefix.Bits(); bit < 128; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	last := netip.AddrFrom16(bytes)
+	if addr.Is4() {
+		return last.Unmap()
+	}
+	return last
+}
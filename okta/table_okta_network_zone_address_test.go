@@ -0,0 +1,90 @@
+package okta
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOktaNetworkZoneAddressList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_network_zone_address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaNetworkZoneAddressListByZone(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_network_zone_address",
+		Columns: []string{"zone_id", "cidr", "list_kind"},
+		Quals:   map[string]interface{}{"zone_id": "nzo2blocklistxxxxxx2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	if n := c.server.countRequests("GET /api/v1/zones?"); n != 0 {
+		t.Errorf("expected the zones not to be listed, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaNetworkZoneAddressListByZoneForbidden(t *testing.T) {
+	c := newTestConnection(t)
+	c.server.fail("/api/v1/zones/nzo1officexxxxxxxxx1", 403, "E0000006")
+
+	_, err := c.query(t, testQuery{
+		Table: "okta_network_zone_address",
+		Quals: map[string]interface{}{"zone_id": "nzo1officexxxxxxxxx1"},
+	})
+	// The Okta error code is only in the response body of the v5 SDK error
+	if err == nil || !strings.Contains(err.Error(), "E0000006") {
+		t.Errorf("expected the 403 to be returned with its Okta error, got %v", err)
+	}
+}
+
+func TestParseNetworkZoneAddress(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"10.0.0.0/8", []string{"10.0.0.0/8"}},
+		{"10.1.2.3/8", []string{"10.0.0.0/8"}},
+		{"10.0.0.1", []string{"10.0.0.1/32"}},
+		{"10.0.0.1-10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"10.0.0.0-10.0.1.255", []string{"10.0.0.0/23"}},
+		{"0.0.0.0-255.255.255.255", []string{"0.0.0.0/0"}},
+		{"255.255.255.254-255.255.255.255", []string{"255.255.255.254/31"}},
+		{"2001:db8::-2001:db8::2", []string{"2001:db8::/127", "2001:db8::2/128"}},
+	}
+	for _, test := range tests {
+		prefixes, err := parseNetworkZoneAddress(test.value)
+		if err != nil {
+			t.Errorf("parseNetworkZoneAddress(%q) returned error: %v", test.value, err)
+			continue
+		}
+		got := make([]string, len(prefixes))
+		for i, prefix := range prefixes {
+			got[i] = prefix.String()
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseNetworkZoneAddress(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "10.0.0.6-10.0.0.1", "10.0.0.1-2001:db8::1", "not an address"} {
+		if _, err := parseNetworkZoneAddress(value); err == nil {
+			t.Errorf("parseNetworkZoneAddress(%q) expected an error", value)
+		}
+	}
+
+	if last := lastAddressOfPrefix(netip.MustParsePrefix("2001:db8::/32")); last.String() != "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff" {
+		t.Errorf("lastAddressOfPrefix(2001:db8::/32) = %v", last)
+	}
+}
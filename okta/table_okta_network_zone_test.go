@@ -0,0 +1,63 @@
+package okta
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestOktaNetworkZoneList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_network_zone",
+		Columns: []string{"id", "name", "type", "zone_kind", "usage", "countries_include", "ip_service_categories_include", "ip_address_match"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaNetworkZoneListByIpAddress(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_network_zone",
+		Columns: []string{"id", "name", "ip_address", "ip_address_match", "ip_address_server_side_criteria"},
+		Quals:   map[string]interface{}{"ip_address": netip.MustParseAddr("10.2.3.4")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaNetworkZoneListByProxyIpAddress(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_network_zone",
+		Columns: []string{"id", "ip_address", "ip_address_match"},
+		Quals:   map[string]interface{}{"ip_address": "172.16.0.10"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaNetworkZoneGetByIpAddress(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_network_zone",
+		Columns: []string{"id", "ip_address_match"},
+		Quals:   map[string]interface{}{"id": "nzo1officexxxxxxxxx1", "ip_address": "192.168.1.7"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows for an address outside the zone, got %v", rows)
+	}
+}
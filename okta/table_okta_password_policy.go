@@ -66,40 +66,18 @@ func listPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 		input.Type = "MFA_ENROLL"
 	}
 
-	policies, resp, err := listPoliciesWithSettings(ctx, *client, input)
-	if err != nil {
-		logger.Error("listPolicies", "list_policies_with_settings_error", err)
+	// The policies API has no documented maximum page size, so a page size is
+	// only requested when the query has a limit
+	input.Limit = pageSize(d, 0)
+
+	paginator := newPaginatorV2(d, "listPolicies", func() ([]*PolicyStructure, *okta.Response, error) {
+		return listPoliciesWithSettings(ctx, *client, input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
 		return nil, err
 	}
 
-	for _, policy := range policies {
-		d.StreamListItem(ctx, policy)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicySet []*okta.Policy
-		resp, err = resp.Next(ctx, &nextPolicySet)
-		if err != nil {
-			logger.Error("listPolicies", "list_policies_with_settings_paging_error", err)
-			return nil, err
-		}
-		for _, policy := range nextPolicySet {
-			d.StreamListItem(ctx, policy)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 // Generic policy returned by
@@ -0,0 +1,15 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaPasswordPolicyList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_password_policy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
@@ -2,7 +2,6 @@ package okta
 
 import (
 	"context"
-	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/okta/okta-sdk-golang/v2/okta/query"
@@ -59,40 +58,18 @@ func listOktaSignonPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.
 		input.Type = "OKTA_SIGN_ON"
 	}
 
-	policies, resp, err := client.Policy.ListPolicies(ctx, input)
-	if err != nil {
-		logger.Error("listOktaSignonPolicies", "list_policies_error", err)
-		return nil, err
-	}
-
-	for _, policy := range policies {
-		d.StreamListItem(ctx, policy)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicySet []*okta.Policy
-		resp, err = resp.Next(ctx, &nextPolicySet)
-		if err != nil {
-			logger.Error("listOktaSignonPolicies", "list_policies_paging_error", err)
-			return nil, err
-		}
-		for _, policy := range nextPolicySet {
-			d.StreamListItem(ctx, policy)
+	// The policies API has no documented maximum page size, so a page size is
+	// only requested when the query has a limit
+	input.Limit = pageSize(d, 0)
 
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
+	paginator := newPaginatorV2(d, "listOktaSignonPolicies", func() ([]*okta.AuthorizationServerPolicy, *okta.Response, error) {
+		return client.Policy.ListPolicies(ctx, input)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		return nil, err
 	}
 
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTION
@@ -124,39 +101,26 @@ func getOktaPolicyRules(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 		return nil, err
 	}
 
-	var rules []oktaV4.ListPolicyRules200ResponseInner
-
-	policyRules, resp, err := client.PolicyAPI.ListPolicyRules(ctx, policyId).Execute()
-	if err != nil {
-		logger.Error("getOktaPolicyRules", "list_policies_error", err)
-		return nil, err
-	}
-
-	rules = append(rules, policyRules...)
-
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicyRules []*oktaV4.ListPolicyRules200ResponseInner
-		resp, err = resp.Next(&nextPolicyRules)
-		if err != nil {
-			logger.Error("getOktaPolicyRules", "list_policies_paging_error", err)
-			return nil, err
-		}
-		for _, r := range nextPolicyRules {
-			rules = append(rules, *r)
-		}
-	}
-
 	var allRules []interface{}
-	for _, rule := range rules {
-		r := rule.GetActualInstance()
+	var parseErr error
+
+	paginator := newPaginatorV4(d, "getOktaPolicyRules", client.PolicyAPI.ListPolicyRules(ctx, policyId).Execute)
+	err = paginator.ForEach(ctx, func(rule oktaV4.ListPolicyRules200ResponseInner) bool {
 		// We need to extract the inner properties; otherwise, the values will be populated as null.
-		result, err := structToMap(r)
+		result, err := structToMap(rule.GetActualInstance())
 		if err != nil {
 			logger.Error("getOktaPolicyRules", "error in parsing the rules for the policy:", policyId, err)
-			return nil, err
+			parseErr = err
+			return false
 		}
 		allRules = append(allRules, result)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
 	}
 
 	return allRules, nil
@@ -189,32 +153,14 @@ func getOktaPolicyAssociatedResources(ctx context.Context, d *plugin.QueryData,
 		return nil, err
 	}
 
-	var mappings []oktaV4.PolicyMapping
-
-	policyMappings, resp, err := client.PolicyAPI.ListPolicyMappings(ctx, policyId).Execute()
+	paginator := newPaginatorV4(d, "getOktaPolicyAssociatedResources", client.PolicyAPI.ListPolicyMappings(ctx, policyId).Execute)
+	mappings, err := paginator.All(ctx)
 	if err != nil {
-		if strings.Contains(strings.ToLower(err.Error()), "not found") || strings.Contains(err.Error(), "404") {
+		if isNotFoundError(err) {
 			return nil, nil
 		}
-		logger.Error("getOktaPolicyAssociatedResources", "list_policies_error", err)
 		return nil, err
 	}
 
-	mappings = append(mappings, policyMappings...)
-
-	// paging
-	for resp.HasNextPage() {
-		var nextPolicyMappings []*oktaV4.PolicyMapping
-		resp, err = resp.Next(&nextPolicyMappings)
-		if err != nil {
-			logger.Error("getOktaPolicyAssociatedResources", "list_policies_paging_error", err)
-			return nil, err
-		}
-
-		for _, mapping := range nextPolicyMappings {
-			mappings = append(mappings, *mapping)
-		}
-	}
-
 	return mappings, nil
 }
@@ -2,6 +2,11 @@ package okta
 
 import (
 	"context"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/okta/okta-sdk-golang/v2/okta/query"
@@ -18,9 +23,9 @@ func tableOktaTrustedOrigin() *plugin.Table {
 		Name:        "okta_trusted_origin",
 		Description: "Trusted Origin is a security-based concept that combines the URI scheme, hostname, and port number of a page.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaTrustedOrigin,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaTrustedOrigin,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaTrustedOrigins,
@@ -37,9 +42,19 @@ func tableOktaTrustedOrigin() *plugin.Table {
 			{Name: "last_updated_by", Type: proto.ColumnType_STRING, Description: "The ID of the user who last updated the trusted origin."},
 			{Name: "origin", Type: proto.ColumnType_STRING, Description: "The origin of the trusted origin."},
 			{Name: "status", Type: proto.ColumnType_STRING, Description: "Current status of the trusted origin. Valid values are 'ACTIVE' or 'INACTIVE'."},
+			{Name: "cors", Type: proto.ColumnType_BOOL, Transform: transform.From(trustedOriginColumn), Description: "True if the origin is allowed to make cross-origin requests to the Okta API."},
+			{Name: "redirect", Type: proto.ColumnType_BOOL, Transform: transform.From(trustedOriginColumn), Description: "True if Okta is allowed to redirect to the origin after sign-in or sign-out."},
+			{Name: "iframe_embed", Type: proto.ColumnType_BOOL, Transform: transform.From(trustedOriginColumn), Description: "True if the origin is allowed to embed Okta pages in an iframe."},
+			{Name: "scheme", Type: proto.ColumnType_STRING, Transform: transform.From(trustedOriginColumn), Description: "Scheme of the origin, e.g. https."},
+			{Name: "host", Type: proto.ColumnType_STRING, Transform: transform.From(trustedOriginColumn), Description: "Host name of the origin, e.g. *.example.com."},
+			{Name: "port", Type: proto.ColumnType_INT, Transform: transform.From(trustedOriginColumn), Description: "Port of the origin, or the default port of its scheme."},
+			{Name: "is_wildcard", Type: proto.ColumnType_BOOL, Transform: transform.From(trustedOriginColumn), Description: "True if the host name of the origin contains a wildcard."},
+			{Name: "risk", Type: proto.ColumnType_STRING, Transform: transform.From(trustedOriginColumn), Description: "Risk of the origin given its scopes: high, medium or low. See risk_reasons for why."},
 
 			// JSON Columns
-			{Name: "scopes", Type: proto.ColumnType_JSON, Description: "The scopes for the trusted origin. Valid values are 'CORS' or 'REDIRECT'."},
+			{Name: "scopes", Type: proto.ColumnType_JSON, Description: "The scopes for the trusted origin. Valid values are 'CORS', 'REDIRECT' or 'IFRAME_EMBED'."},
+			{Name: "iframe_embed_allowed_okta_apps", Type: proto.ColumnType_JSON, Transform: transform.From(trustedOriginColumn), Description: "The Okta apps the origin is allowed to embed, e.g. OKTA_ENDUSER for the End-User Dashboard. Empty if only the sign-in page can be embedded."},
+			{Name: "risk_reasons", Type: proto.ColumnType_JSON, Transform: transform.From(trustedOriginColumn), Description: "Why the origin has a high or medium risk."},
 
 			// Steampipe Columns
 			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Name"), Description: titleDescription},
@@ -65,47 +80,16 @@ func listOktaTrustedOrigins(ctx context.Context, d *plugin.QueryData, _ *plugin.
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
-	origins, resp, err := client.TrustedOrigin.ListOrigins(ctx, &input)
-	if err != nil {
-		logger.Error("listOktaTrustedOrigins", "list_origins_error", err)
+	paginator := newPaginatorV2(d, "listOktaTrustedOrigins", func() ([]*TrustedOrigin, *okta.Response, error) {
+		return listTrustedOrigins(ctx, client, "/api/v1/trustedOrigins"+input.String())
+	})
+	if err := paginator.Stream(ctx); err != nil {
 		return nil, err
 	}
 
-	for _, origin := range origins {
-		d.StreamListItem(ctx, origin)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextOriginSet []*okta.TrustedOrigin
-		resp, err = resp.Next(ctx, &nextOriginSet)
-		if err != nil {
-			logger.Error("listOktaTrustedOrigins", "list_origins_paging_error", err)
-			return nil, err
-		}
-		for _, origin := range nextOriginSet {
-			d.StreamListItem(ctx, origin)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -126,11 +110,204 @@ func getOktaTrustedOrigin(ctx context.Context, d *plugin.QueryData, h *plugin.Hy
 		return nil, err
 	}
 
-	app, _, err := client.TrustedOrigin.GetOrigin(ctx, trustedOriginId)
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", "/api/v1/trustedOrigins/"+url.PathEscape(trustedOriginId), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var origin *TrustedOrigin
+	resp, err := requestExecutor.Do(ctx, req, &origin)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaTrustedOrigin", "get_origin_error", err)
+		return nil, handleOktaError(d, err)
+	}
+
+	return origin, nil
+}
+
+// listTrustedOrigins lists trusted origins, whose IFRAME_EMBED scopes the SDK
+// types don't fully cover.
+func listTrustedOrigins(ctx context.Context, client *okta.Client, url string) ([]*TrustedOrigin, *okta.Response, error) {
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var origins []*TrustedOrigin
+
+	resp, err := requestExecutor.Do(ctx, req, &origins)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return origins, resp, nil
+}
+
+// TrustedOrigin is a trusted origin with the allowed Okta apps of its scopes,
+// which the SDK Scope type is missing.
+type TrustedOrigin struct {
+	okta.TrustedOrigin
+	Scopes []*TrustedOriginScope `json:"scopes,omitempty"`
+}
+
+type TrustedOriginScope struct {
+	AllowedOktaApps []string `json:"allowedOktaApps,omitempty"`
+	StringValue     string   `json:"stringValue,omitempty"`
+	Type            string   `json:"type,omitempty"`
+}
+
+//// TRANSFORM FUNCTIONS
+
+// trustedOriginColumn returns the flattened scopes, the parts of the origin
+// and the risk of a trusted origin, named after the column.
+func trustedOriginColumn(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	origin, ok := d.HydrateItem.(*TrustedOrigin)
+	if !ok || origin == nil {
+		return nil, nil
+	}
+
+	switch d.ColumnName {
+	case "cors":
+		return origin.hasScope("CORS"), nil
+	case "redirect":
+		return origin.hasScope("REDIRECT"), nil
+	case "iframe_embed":
+		return origin.hasScope("IFRAME_EMBED"), nil
+	case "iframe_embed_allowed_okta_apps":
+		return origin.iframeEmbedAllowedOktaApps(), nil
+	}
+
+	parsed, err := parseTrustedOrigin(origin.Origin)
+	if err != nil {
+		if d.ColumnName != "risk" && d.ColumnName != "risk_reasons" {
+			return nil, nil
+		}
+		// Origins that can't be parsed are rated on their scopes only
+		parsed = &trustedOriginURL{}
+	}
+	switch d.ColumnName {
+	case "scheme":
+		return parsed.scheme, nil
+	case "host":
+		return parsed.host, nil
+	case "port":
+		return parsed.port, nil
+	case "is_wildcard":
+		return parsed.isWildcard(), nil
+	}
+
+	risk, reasons := trustedOriginRisk(origin, parsed)
+	if d.ColumnName == "risk_reasons" {
+		return reasons, nil
+	}
+	return risk, nil
+}
+
+//// UTILITY FUNCTIONS
+
+func (origin *TrustedOrigin) hasScope(scopeType string) bool {
+	return slices.ContainsFunc(origin.Scopes, func(scope *TrustedOriginScope) bool {
+		return scope != nil && scope.Type == scopeType
+	})
+}
+
+func (origin *TrustedOrigin) iframeEmbedAllowedOktaApps() []string {
+	var apps []string
+	for _, scope := range origin.Scopes {
+		if scope != nil && scope.Type == "IFRAME_EMBED" {
+			apps = append(apps, scope.AllowedOktaApps...)
+		}
+	}
+	return apps
+}
+
+type trustedOriginURL struct {
+	scheme string
+	host   string
+	port   *int
+}
+
+// parseTrustedOrigin parses an origin such as https://*.example.com:8443 into
+// its scheme, host name and port, defaulting to the port of the scheme.
+func parseTrustedOrigin(origin string) (*trustedOriginURL, error) {
+	u, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil {
 		return nil, err
 	}
+	parsed := &trustedOriginURL{
+		scheme: strings.ToLower(u.Scheme),
+		host:   strings.ToLower(u.Hostname()),
+	}
+	if port := u.Port(); port != "" {
+		number, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, err
+		}
+		parsed.port = &number
+	} else if number, ok := map[string]int{"http": 80, "https": 443}[parsed.scheme]; ok {
+		parsed.port = &number
+	}
+	return parsed, nil
+}
+
+func (u *trustedOriginURL) isWildcard() bool {
+	return strings.Contains(u.host, "*")
+}
+
+// isLoopback returns true for origins only reachable from the device of the
+// user, e.g. a local development server.
+func (u *trustedOriginURL) isLoopback() bool {
+	if u.host == "localhost" || strings.HasSuffix(u.host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(u.host)
+	return ip != nil && ip.IsLoopback()
+}
 
-	return app, nil
+// trustedOriginRisk rates a trusted origin by what an attacker controlling a
+// matching page could do with its scopes:
+//   - high: a wildcard origin with any scope, or a plain http origin allowed
+//     CORS or redirects, whose traffic can be intercepted;
+//   - medium: a plain http loopback origin allowed CORS or redirects, or an
+//     origin allowed to embed Okta apps besides the sign-in page;
+//   - low: any other origin.
+func trustedOriginRisk(origin *TrustedOrigin, parsed *trustedOriginURL) (string, []string) {
+	var high, medium []string
+
+	cors, redirect, iframeEmbed := origin.hasScope("CORS"), origin.hasScope("REDIRECT"), origin.hasScope("IFRAME_EMBED")
+	var scopes []string
+	for _, scope := range []struct {
+		enabled bool
+		name    string
+	}{{cors, "CORS"}, {redirect, "REDIRECT"}, {iframeEmbed, "IFRAME_EMBED"}} {
+		if scope.enabled {
+			scopes = append(scopes, scope.name)
+		}
+	}
+
+	if parsed.isWildcard() && len(scopes) > 0 {
+		high = append(high, "Wildcard origin with "+strings.Join(scopes, ", ")+" scope")
+	}
+	if parsed.scheme == "http" && (cors || redirect) {
+		httpScopes := strings.Join(slices.DeleteFunc(slices.Clone(scopes), func(scope string) bool { return scope == "IFRAME_EMBED" }), ", ")
+		if parsed.isLoopback() {
+			medium = append(medium, "Loopback http origin with "+httpScopes+" scope")
+		} else {
+			high = append(high, "Unencrypted http origin with "+httpScopes+" scope")
+		}
+	}
+	if apps := origin.iframeEmbedAllowedOktaApps(); len(apps) > 0 {
+		medium = append(medium, "Origin can embed Okta apps: "+strings.Join(apps, ", "))
+	}
+
+	switch {
+	case len(high) > 0:
+		return "high", append(high, medium...)
+	case len(medium) > 0:
+		return "medium", medium
+	}
+	return "low", nil
 }
@@ -0,0 +1,30 @@
+package okta
+
+import "testing"
+
+func TestOktaTrustedOriginList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_trusted_origin",
+		Columns: []string{"id", "cors", "redirect", "iframe_embed", "iframe_embed_allowed_okta_apps", "scheme", "host", "port", "is_wildcard", "risk", "risk_reasons"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaTrustedOriginGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_trusted_origin",
+		Columns: []string{"id", "name", "status", "scopes", "iframe_embed_allowed_okta_apps", "risk"},
+		Quals:   map[string]interface{}{"id": "tos4portalxxxxxxxxx4"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
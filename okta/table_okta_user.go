@@ -21,9 +21,9 @@ func tableOktaUser() *plugin.Table {
 		Name:        "okta_user",
 		Description: "Represents an Okta user account.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaUser,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaUser,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaUsers,
@@ -37,15 +37,16 @@ func tableOktaUser() *plugin.Table {
 				{Name: "status", Require: plugin.Optional},
 				{Name: "filter", Require: plugin.Optional},
 				{Name: "last_updated", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
+				{Name: "incremental", Require: plugin.Optional},
 			},
 		},
 		HydrateConfig: []plugin.HydrateConfig{
 			{
-				Func: listUserGroups,
+				Func:           listUserGroups,
 				MaxConcurrency: 10,
 			},
 			{
-				Func: listAssignedRolesForUser,
+				Func:           listAssignedRolesForUser,
 				MaxConcurrency: 10,
 			},
 		},
@@ -66,6 +67,17 @@ func tableOktaUser() *plugin.Table {
 			{Name: "status", Type: proto.ColumnType_STRING, Description: "Current status of user. Can be one of the STAGED, PROVISIONED, ACTIVE, RECOVERY, LOCKED_OUT, PASSWORD_EXPIRED, SUSPENDED, or DEPROVISIONED."},
 			{Name: "status_changed", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp when status last changed."},
 			{Name: "transitioning_to_status", Type: proto.ColumnType_STRING, Description: "Target status of an in-progress asynchronous status transition."},
+			{Name: "credentials_provider_type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Credentials.Provider.Type"), Description: "Type of the provider that authenticates the user. Can be one of OKTA, ACTIVE_DIRECTORY, LDAP, FEDERATION, SOCIAL or IMPORT."},
+			{Name: "credentials_provider_name", Type: proto.ColumnType_STRING, Transform: transform.FromField("Credentials.Provider.Name"), Description: "Name of the provider that authenticates the user, e.g. OKTA or the name of the directory."},
+			{Name: "has_password", Type: proto.ColumnType_BOOL, Transform: transform.From(userHasPassword), Description: "True if the user has a password credential. Okta never returns the password itself."},
+			{Name: "has_recovery_question", Type: proto.ColumnType_BOOL, Transform: transform.From(userHasRecoveryQuestion), Description: "True if the user has set a recovery question."},
+			{Name: "user_type_id", Type: proto.ColumnType_STRING, Transform: transform.FromField("Type.Id"), Description: "ID of the user type of the user."},
+			{Name: "user_type_name", Type: proto.ColumnType_STRING, Hydrate: getOktaUserTypeName, Transform: transform.FromValue(), Description: "Name of the user type of the user, e.g. user for the default user type."},
+			{Name: "employee_number", Type: proto.ColumnType_STRING, Transform: transform.From(userProfile), Description: "Organization or company assigned unique identifier for the user."},
+			{Name: "manager_id", Type: proto.ColumnType_STRING, Transform: transform.From(userProfile), Description: "ID of the user's manager, as set in the managerId profile property. Not necessarily an Okta user ID."},
+			{Name: "manager", Type: proto.ColumnType_STRING, Transform: transform.From(userProfile), Description: "Display name of the user's manager, as set in the manager profile property."},
+			{Name: "incremental", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("incremental"), Description: "If true, only the users updated since the last incremental sync of the connection are returned, including deprovisioned users."},
+			{Name: "tombstone", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Status").Transform(isDeprovisionedStatus), Description: "True if the user is DEPROVISIONED, i.e. should be removed from a snapshot kept up to date with incremental syncs."},
 
 			// JSON Columns
 			{Name: "profile", Type: proto.ColumnType_JSON, Description: "User profile properties."},
@@ -97,12 +109,7 @@ func listOktaUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 
 	// If the requested number of items is less than the paging max limit
 	// set the limit to that instead
-	limit := d.QueryContext.Limit
-	if d.QueryContext.Limit != nil {
-		if *limit < input.Limit {
-			input.Limit = *limit
-		}
-	}
+	input.Limit = pageSize(d, input.Limit)
 
 	equalQuals := d.EqualsQuals
 	quals := d.Quals
@@ -129,40 +136,101 @@ func listOktaUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 		input.Filter = strings.Join(filter, " and ")
 	}
 
-	users, resp, err := client.User.ListUsers(ctx, &input)
+	if isIncrementalSync(d) {
+		return listOktaUsersIncremental(ctx, d, client, input)
+	}
+
+	buildUserGroupsIndex := newUserGroupsIndexTrigger(ctx, d)
+	var indexErr error
+	paginator := newPaginatorV2(d, "listOktaUsers", func() ([]*okta.User, *okta.Response, error) {
+		return client.User.ListUsers(ctx, &input)
+	})
+	err = paginator.ForEach(ctx, func(user *okta.User) bool {
+		if indexErr = buildUserGroupsIndex(); indexErr != nil {
+			return false
+		}
+		d.StreamListItem(ctx, user)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	if indexErr != nil {
+		logger.Error("listOktaUsers", "get_user_groups_index_error", indexErr)
+		return nil, indexErr
+	}
+
+	return nil, nil
+}
+
+// listOktaUsersIncremental lists the users updated since the last incremental
+// sync, including deprovisioned users, and saves the sync state once every
+// changed user has been listed.
+func listOktaUsersIncremental(ctx context.Context, d *plugin.QueryData, client *okta.Client, input query.Params) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	state, err := loadSyncState(d)
 	if err != nil {
-		logger.Error("listOktaUsers", "list_users_error", err)
+		logger.Error("listOktaUsersIncremental", "load_sync_state_error", err)
 		return nil, err
 	}
 
-	for _, user := range users {
+	// The state can only be advanced if the listing isn't narrowed down by other quals
+	advanceState := input.Filter == "" && d.QueryContext.Limit == nil
+
+	// Unlike a plain listing, a filtered listing includes DEPROVISIONED users
+	if state.LastUpdated != nil {
+		syncFilter := fmt.Sprintf("lastUpdated ge \"%s\"", syncSince(state.LastUpdated))
+		if input.Filter != "" {
+			input.Filter = fmt.Sprintf("(%s) and %s", input.Filter, syncFilter)
+		} else {
+			input.Filter = syncFilter
+		}
+	}
+
+	listing := newSyncListing(state)
+	complete := true
+	buildUserGroupsIndex := newUserGroupsIndexTrigger(ctx, d)
+	var indexErr error
+	paginator := newPaginatorV2(d, "listOktaUsersIncremental", func() ([]*okta.User, *okta.Response, error) {
+		return client.User.ListUsers(ctx, &input)
+	})
+	err = paginator.ForEach(ctx, func(user *okta.User) bool {
+		if indexErr = buildUserGroupsIndex(); indexErr != nil {
+			complete = false
+			return false
+		}
+		// Users in the skew window of the previous sync may already have been returned by it
+		if listing.seen(user.Id, user.LastUpdated) {
+			return true
+		}
 		d.StreamListItem(ctx, user)
 
 		// Context can be cancelled due to manual cancellation or the limit has been hit
 		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
+			complete = false
+			return false
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if indexErr != nil {
+		logger.Error("listOktaUsersIncremental", "get_user_groups_index_error", indexErr)
+		return nil, indexErr
 	}
 
-	// paging
-	for resp.HasNextPage() {
-		var nextUserSet []*okta.User
-		resp, err = resp.Next(ctx, &nextUserSet)
-		if err != nil {
-			logger.Error("listOktaUsers", "list_users_paging_error", err)
+	if advanceState && complete && ctx.Err() == nil {
+		if err := saveSyncState(d, listing.next); err != nil {
+			logger.Error("listOktaUsersIncremental", "save_sync_state_error", err)
 			return nil, err
 		}
-		for _, user := range nextUserSet {
-			d.StreamListItem(ctx, user)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
 	}
 
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -187,10 +255,11 @@ func getOktaUser(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 		return nil, err
 	}
 
-	user, _, err := client.User.GetUser(ctx, userId)
+	user, resp, err := client.User.GetUser(ctx, userId)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaUser", "get_user_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return user, nil
@@ -206,23 +275,21 @@ func listUserGroups(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateD
 		return nil, err
 	}
 
-	groups, resp, err := client.User.ListUserGroups(ctx, user.Id)
-	if err != nil {
-		logger.Error("listUserGroups", "list_user_groups_error", err)
-		if strings.Contains(err.Error(), "Not found") {
-			return nil, nil
+	// Listing the groups of every user of a large org takes one call per user,
+	// listing the members of every group is usually orders of magnitude faster.
+	// The index is built by listOktaUsers once enough users have been listed,
+	// and only serves the rows of that listing. Users missing from the index,
+	// e.g. created since it was built, are looked up with one call.
+	if index, ok := listingUserGroupsIndex(d); ok {
+		if groups, ok := index[user.Id]; ok {
+			return groups, nil
 		}
-		return nil, err
 	}
 
-	for resp.HasNextPage() {
-		var nextGroupSet []*okta.Group
-		resp, err = resp.Next(ctx, &nextGroupSet)
-		if err != nil {
-			logger.Error("listUserGroups", "list_user_groups_paging_error", err)
-			return nil, err
-		}
-		groups = append(groups, nextGroupSet...)
+	groups, err := listOktaUserGroups(ctx, d, client, user.Id)
+	if err != nil {
+		logger.Error("listUserGroups", "list_user_groups_error", err)
+		return nil, err
 	}
 
 	return groups, nil
@@ -238,23 +305,39 @@ func listAssignedRolesForUser(ctx context.Context, d *plugin.QueryData, h *plugi
 		return nil, err
 	}
 
-	roles, resp, err := client.User.ListAssignedRolesForUser(ctx, user.Id, &query.Params{})
+	paginator := newPaginatorV2(d, "listAssignedRolesForUser", func() ([]*okta.Role, *okta.Response, error) {
+		return client.User.ListAssignedRolesForUser(ctx, user.Id, &query.Params{})
+	})
+	roles, err := paginator.All(ctx)
 	if err != nil {
-		logger.Error("listAssignedRolesForUser", "list_assigned_roles_for_user_error", err)
 		return nil, err
 	}
 
-	for resp.HasNextPage() {
-		var nextRolesSet []*okta.Role
-		resp, err = resp.Next(ctx, &nextRolesSet)
-		if err != nil {
-			logger.Error("listAssignedRolesForUser", "list_assigned_roles_for_user_paging_error", err)
-			return nil, err
+	return roles, nil
+}
+
+// getOktaUserTypeName resolves the name of the user type of the user from the
+// cached list of user types, rather than getting the user type of every user.
+func getOktaUserTypeName(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	user := h.Item.(*okta.User)
+	if user.Type == nil || user.Type.Id == "" {
+		return nil, nil
+	}
+
+	userTypes, err := listCachedOktaUserTypes(ctx, d)
+	if err != nil {
+		logger.Error("getOktaUserTypeName", "list_user_types_error", err)
+		return nil, err
+	}
+
+	for _, userType := range userTypes {
+		if userType.Id == user.Type.Id {
+			return userType.Name, nil
 		}
-		roles = append(roles, nextRolesSet...)
 	}
 
-	return roles, nil
+	return nil, nil
 }
 
 //// TRANSFORM FUNCTION
@@ -288,6 +371,32 @@ func transformUserGroups(ctx context.Context, d *transform.TransformData) (inter
 	return groupsData, nil
 }
 
+// The credentials of users without a password or recovery question have no
+// password or recovery_question property.
+func userHasPassword(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	user := d.HydrateItem.(*okta.User)
+	if user.Credentials == nil {
+		return false, nil
+	}
+	return user.Credentials.Password != nil, nil
+}
+
+func userHasRecoveryQuestion(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	user := d.HydrateItem.(*okta.User)
+	if user.Credentials == nil {
+		return false, nil
+	}
+	return user.Credentials.RecoveryQuestion != nil, nil
+}
+
+func isDeprovisionedStatus(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	status, ok := d.Value.(string)
+	if !ok {
+		return nil, nil
+	}
+	return status == "DEPROVISIONED", nil
+}
+
 //// other useful functions
 
 func buildUserQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
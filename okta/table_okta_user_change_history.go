@@ -0,0 +1,398 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// System Log event type prefixes of the changes made to a user, and the
+// change category of each
+var userChangeEventTypes = []struct {
+	prefix   string
+	category string
+}{
+	{"user.lifecycle.", "lifecycle"},
+	{"group.user_membership.", "group_membership"},
+	{"application.user_membership.", "application_membership"},
+}
+
+// Status of a user after a successful lifecycle event. Other lifecycle events,
+// e.g. user.lifecycle.password_mode_change, don't change the status.
+var userLifecycleStatus = map[string]string{
+	"user.lifecycle.create":           "STAGED",
+	"user.lifecycle.activate":         "ACTIVE",
+	"user.lifecycle.reactivate":       "PROVISIONED",
+	"user.lifecycle.suspend":          "SUSPENDED",
+	"user.lifecycle.unsuspend":        "ACTIVE",
+	"user.lifecycle.deactivate":       "DEPROVISIONED",
+	"user.lifecycle.delete.initiated": "DELETED",
+}
+
+// Status of a user before a successful lifecycle event, for the events that
+// can only be made from a single status. A created user has no status before.
+var userLifecyclePriorStatus = map[string]*string{
+	"user.lifecycle.create":           nil,
+	"user.lifecycle.suspend":          types.String("ACTIVE"),
+	"user.lifecycle.unsuspend":        types.String("SUSPENDED"),
+	"user.lifecycle.reactivate":       types.String("DEPROVISIONED"),
+	"user.lifecycle.delete.initiated": types.String("DEPROVISIONED"),
+}
+
+//// TABLE DEFINITION
+
+func tableOktaUserChangeHistory() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_user_change_history",
+		Description: "Represents the lifecycle, group membership and application membership changes of Okta users, from the System Log.",
+		List: &plugin.ListConfig{
+			Hydrate: listOktaUserChangeHistory,
+			KeyColumns: plugin.KeyColumnSlice{
+				// https://developer.okta.com/docs/reference/api/system-log/#request-parameters
+				{Name: "user_id", Require: plugin.Optional},
+				{Name: "published", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "Unique key of the user the change was made to."},
+			{Name: "user_login", Type: proto.ColumnType_STRING, Description: "Login of the user the change was made to."},
+			{Name: "published", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Event.Published"), Description: "Timestamp when the change was made. Defaults to the last 7 days if not set in the query."},
+			{Name: "event_type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.EventType"), Description: "Type of the event, e.g. user.lifecycle.activate or group.user_membership.add."},
+			{Name: "change_category", Type: proto.ColumnType_STRING, Description: "Category of the change. Can be one of lifecycle, group_membership or application_membership."},
+			{Name: "status_before", Type: proto.ColumnType_STRING, Description: "Status of the user before the change, e.g. ACTIVE, SUSPENDED, DEPROVISIONED or DELETED. Null if unknown, i.e. the first lifecycle event of the user since the change can be made from several statuses, or if the user didn't exist."},
+			{Name: "status_after", Type: proto.ColumnType_STRING, Description: "Status of the user after the change, e.g. ACTIVE, SUSPENDED, DEPROVISIONED or DELETED. Null if unknown."},
+
+			// Other Columns
+			{Name: "uuid", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Uuid"), Description: "Unique identifier of the event."},
+			{Name: "display_message", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.DisplayMessage"), Description: "Human readable description of the event."},
+			{Name: "outcome_result", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Outcome.Result"), Description: "Result of the change. Can be one of SUCCESS, FAILURE, SKIPPED, ALLOW, DENY, CHALLENGE or UNKNOWN."},
+			{Name: "outcome_reason", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Outcome.Reason"), Description: "Reason of the result of the change."},
+			{Name: "severity", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Severity"), Description: "Severity of the event. Can be one of DEBUG, INFO, WARN or ERROR."},
+			{Name: "actor_id", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Actor.Id"), Description: "Unique key of the actor that made the change."},
+			{Name: "actor_type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Actor.Type"), Description: "Type of the actor that made the change, e.g. User or PublicClientApp."},
+			{Name: "actor_alternate_id", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Actor.AlternateId"), Description: "Alternative identifier of the actor, e.g. the login of a user."},
+			{Name: "actor_display_name", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Actor.DisplayName"), Description: "Display name of the actor that made the change."},
+			{Name: "transaction_id", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Transaction.Id"), Description: "Unique key of the transaction of the event, shared by every event of the same request."},
+			{Name: "transaction_type", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.Transaction.Type"), Description: "Type of the transaction. Can be one of WEB or JOB."},
+			{Name: "group_id", Type: proto.ColumnType_STRING, Description: "Unique key of the group of a group membership change."},
+			{Name: "group_name", Type: proto.ColumnType_STRING, Description: "Name of the group of a group membership change."},
+			{Name: "app_id", Type: proto.ColumnType_STRING, Description: "Unique key of the application of an application membership change."},
+			{Name: "app_name", Type: proto.ColumnType_STRING, Description: "Name of the application of an application membership change."},
+			{Name: "client_ip_address", Type: proto.ColumnType_IPADDR, Transform: transform.FromField("Event.Client.IpAddress"), Description: "IP address of the client that made the change."},
+
+			// JSON Columns
+			{Name: "groups_after", Type: proto.ColumnType_JSON, Description: "The groups the user is a direct member of after the change, replayed back from the current groups of the user."},
+			{Name: "apps_after", Type: proto.ColumnType_JSON, Description: "The applications the user is assigned to after the change, replayed back from the current applications of the user."},
+			{Name: "target", Type: proto.ColumnType_JSON, Transform: transform.FromField("Event.Target"), Description: "The entities the event was performed on."},
+			{Name: "debug_context", Type: proto.ColumnType_JSON, Transform: transform.FromField("Event.DebugContext.DebugData"), Description: "Additional information about the event for debugging."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("Event.DisplayMessage"), Description: titleDescription},
+		}),
+	}
+}
+
+type UserChangeInfo struct {
+	UserId         string
+	UserLogin      string
+	ChangeCategory string
+	StatusBefore   *string
+	StatusAfter    *string
+	GroupId        string
+	GroupName      string
+	AppId          string
+	AppName        string
+	GroupsAfter    []UserChangeMembership
+	AppsAfter      []UserChangeMembership
+	Event          *okta.LogEvent
+}
+
+// UserChangeMembership is a group or application a user is a member of.
+type UserChangeMembership struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// userChangeState is the state of a user, replayed from its current state and
+// its change events.
+type userChangeState struct {
+	status *string
+	groups map[string]string
+	apps   map[string]string
+}
+
+//// LIST FUNCTION
+
+func listOktaUserChangeHistory(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("listOktaUserChangeHistory", "connect_error", err)
+		return nil, err
+	}
+
+	// Default maximum limit set as per documentation. Every event up to now
+	// is needed to replay the state of the users, so the limit of the query
+	// isn't applied to the page size.
+	// https://developer.okta.com/docs/reference/api/system-log/#request-parameters
+	input := query.Params{
+		Limit:     1000,
+		SortOrder: "ASCENDING",
+	}
+
+	eventTypeFilters := []string{}
+	for _, t := range userChangeEventTypes {
+		eventTypeFilters = append(eventTypeFilters, fmt.Sprintf("eventType sw \"%s\"", t.prefix))
+	}
+	filter := "(" + strings.Join(eventTypeFilters, " or ") + ")"
+	if userId := d.EqualsQualString("user_id"); userId != "" {
+		filter += fmt.Sprintf(" and target.id eq \"%s\"", escapeFilterValue(userId))
+	}
+	input.Filter = filter
+
+	// since and until are inclusive, the exact bounds are applied by Postgres
+	if d.Quals["published"] != nil {
+		for _, q := range d.Quals["published"].Quals {
+			timeString := q.Value.GetTimestampValue().AsTime().UTC().Format(filterTimeFormat)
+			switch q.Operator {
+			case ">", ">=":
+				input.Since = timeString
+			case "<", "<=":
+				input.Until = timeString
+			case "=":
+				input.Since = timeString
+				input.Until = timeString
+			}
+		}
+	}
+
+	// The state of the users is replayed back from their current state, so
+	// the events after the time range are listed too. Without until the System
+	// Log is polled, i.e. always returns a next link.
+	var until time.Time
+	if input.Until != "" {
+		until, _ = time.Parse(filterTimeFormat, input.Until)
+	}
+	input.Until = time.Now().UTC().Format(filterTimeFormat)
+
+	var changes []*UserChangeInfo
+	paginator := newPaginatorV2(d, "listOktaUserChangeHistory", func() ([]*okta.LogEvent, *okta.Response, error) {
+		return client.LogEvent.GetLogs(ctx, &input)
+	})
+	err = paginator.ForEach(ctx, func(event *okta.LogEvent) bool {
+		changes = append(changes, userChangeInfo(event))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := getUserChangeStates(ctx, d, client, changes)
+	if err != nil {
+		logger.Error("listOktaUserChangeHistory", "get_user_states_error", err)
+		return nil, err
+	}
+	replayUserChanges(changes, states)
+
+	for _, change := range changes {
+		if !until.IsZero() && change.Event.Published != nil && change.Event.Published.After(until) {
+			break
+		}
+		d.StreamListItem(ctx, change)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
+	}
+
+	return nil, nil
+}
+
+//// UTILITY FUNCTION
+
+// userChangeInfo picks the user, group and application the change was made to
+// out of the targets of the event.
+func userChangeInfo(event *okta.LogEvent) *UserChangeInfo {
+	info := &UserChangeInfo{Event: event}
+
+	for _, t := range userChangeEventTypes {
+		if strings.HasPrefix(event.EventType, t.prefix) {
+			info.ChangeCategory = t.category
+			break
+		}
+	}
+
+	for _, target := range event.Target {
+		if target == nil {
+			continue
+		}
+		switch target.Type {
+		case "User":
+			if info.UserId == "" {
+				info.UserId = target.Id
+				info.UserLogin = target.AlternateId
+			}
+		case "UserGroup":
+			info.GroupId = target.Id
+			info.GroupName = target.DisplayName
+		case "AppInstance":
+			info.AppId = target.Id
+			info.AppName = target.DisplayName
+		}
+	}
+
+	return info
+}
+
+// getUserChangeStates returns the current status, groups and applications of
+// every user changed, with one call each per user.
+func getUserChangeStates(ctx context.Context, d *plugin.QueryData, client *okta.Client, changes []*UserChangeInfo) (map[string]*userChangeState, error) {
+	var users []*okta.User
+	seen := map[string]bool{}
+	for _, change := range changes {
+		if change.UserId != "" && !seen[change.UserId] {
+			seen[change.UserId] = true
+			users = append(users, &okta.User{Id: change.UserId})
+		}
+	}
+
+	states := map[string]*userChangeState{}
+	var mutex sync.Mutex
+	err := forEachUserConcurrently(ctx, d, "listOktaUserChangeHistory.getUserChangeStates", users, func(user *okta.User) error {
+		state, err := getUserChangeState(ctx, d, client, user.Id)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		states[user.Id] = state
+		mutex.Unlock()
+		return nil
+	})
+	return states, err
+}
+
+// getUserChangeState returns the current state of a user. Deleted users have
+// no status, groups or applications.
+func getUserChangeState(ctx context.Context, d *plugin.QueryData, client *okta.Client, userId string) (*userChangeState, error) {
+	state := &userChangeState{groups: map[string]string{}, apps: map[string]string{}}
+
+	user, resp, err := client.User.GetUser(ctx, userId)
+	err = withResponseStatus(err, resp)
+	if err != nil {
+		if isNotFoundError(err) {
+			return state, nil
+		}
+		return nil, handleOktaError(d, err)
+	}
+	if user.Status != "" {
+		state.status = &user.Status
+	}
+
+	groups, err := listOktaUserGroups(ctx, d, client, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.Profile != nil {
+			state.groups[group.Id] = group.Profile.Name
+		} else {
+			state.groups[group.Id] = ""
+		}
+	}
+
+	input := query.Params{Filter: fmt.Sprintf("user.id eq \"%s\"", escapeFilterValue(userId)), Limit: 200}
+	paginator := newPaginatorV2(d, "listOktaUserChangeHistory.listUserApplications", func() ([]*okta.Application, *okta.Response, error) {
+		return listApplications(ctx, client, &input)
+	})
+	err = paginator.ForEach(ctx, func(app *okta.Application) bool {
+		state.apps[app.Id] = app.Label
+		return true
+	})
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// replayUserChanges sets the status of the users before and after each change,
+// and their memberships after each change. Memberships are replayed back from
+// the current state of the users, undoing the changes from the newest one.
+// Statuses are replayed forward from the status before the first lifecycle
+// change of each user, or its current status if it had none. Only successful
+// changes are replayed.
+func replayUserChanges(changes []*UserChangeInfo, states map[string]*userChangeState) {
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		state, ok := states[change.UserId]
+		if !ok {
+			continue
+		}
+		change.GroupsAfter = userChangeMemberships(state.groups)
+		change.AppsAfter = userChangeMemberships(state.apps)
+		if !userChangeSucceeded(change) {
+			continue
+		}
+
+		switch {
+		case change.GroupId != "" && strings.HasSuffix(change.Event.EventType, ".add"):
+			delete(state.groups, change.GroupId)
+		case change.GroupId != "" && strings.HasSuffix(change.Event.EventType, ".remove"):
+			state.groups[change.GroupId] = change.GroupName
+		case change.AppId != "" && strings.HasSuffix(change.Event.EventType, ".add"):
+			delete(state.apps, change.AppId)
+		case change.AppId != "" && strings.HasSuffix(change.Event.EventType, ".remove"):
+			state.apps[change.AppId] = change.AppName
+		}
+
+		// The status before the earliest lifecycle change is only known if
+		// the change can only be made from a single status
+		if _, ok := userLifecycleStatus[change.Event.EventType]; ok {
+			state.status = userLifecyclePriorStatus[change.Event.EventType]
+		}
+	}
+
+	for _, change := range changes {
+		state, ok := states[change.UserId]
+		if !ok {
+			continue
+		}
+		change.StatusBefore = state.status
+		if userChangeSucceeded(change) {
+			if status, ok := userLifecycleStatus[change.Event.EventType]; ok {
+				state.status = &status
+			}
+		}
+		change.StatusAfter = state.status
+	}
+}
+
+func userChangeSucceeded(change *UserChangeInfo) bool {
+	outcome := change.Event.Outcome
+	return outcome == nil || outcome.Result == "" || outcome.Result == "SUCCESS"
+}
+
+// userChangeMemberships returns the memberships of a user sorted by ID.
+func userChangeMemberships(memberships map[string]string) []UserChangeMembership {
+	result := make([]UserChangeMembership, 0, len(memberships))
+	for id, name := range memberships {
+		result = append(result, UserChangeMembership{Id: id, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
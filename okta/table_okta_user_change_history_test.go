@@ -0,0 +1,83 @@
+package okta
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOktaUserChangeHistoryList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user_change_history",
+		Columns: []string{"published", "user_id", "event_type", "outcome_result", "status_before", "status_after", "groups_after", "apps_after"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaUserChangeHistoryListInTimeRange(t *testing.T) {
+	c := newTestConnection(t)
+
+	published := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user_change_history",
+		Columns: []string{"published", "user_id", "event_type", "status_after", "groups_after", "apps_after"},
+		Quals:   map[string]interface{}{"published": published},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The events after the time range are listed to replay the memberships
+	// back from the current ones, but aren't returned
+	found := false
+	for _, row := range rows {
+		if row["published"].(string) > published.Format(time.RFC3339) {
+			t.Errorf("expected no event after %s, got %v", published, row)
+		}
+		if row["published"] != published.Format(time.RFC3339) {
+			continue
+		}
+		found = true
+		groups, _ := json.Marshal(row["groups_after"])
+		apps, _ := json.Marshal(row["apps_after"])
+		if string(groups) != `[{"id":"00g1everyonexxxxxxx1","name":"Everyone"},{"id":"00g2engineeringxxxx2","name":"Engineering"},{"id":"00g4contractorsxxxx4","name":"Contractors"}]` ||
+			string(apps) != `[{"id":"0oa1githubxxxxxxxxx1","name":"GitHub"},{"id":"0oa2slackxxxxxxxxxx2","name":"Slack"}]` ||
+			row["status_after"] != "ACTIVE" {
+			t.Errorf("unexpected state after the change: %v", row)
+		}
+	}
+	if !found {
+		t.Errorf("expected the event published at %s, got %v", published, rows)
+	}
+}
+
+func TestOktaUserChangeHistoryListByUser(t *testing.T) {
+	c := newTestConnection(t)
+
+	_, err := c.query(t, testQuery{
+		Table:   "okta_user_change_history",
+		Columns: []string{"uuid"},
+		Quals:   map[string]interface{}{"user_id": `00u1" or target.id sw "`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The user ID can't end the string literal of the filter
+	for _, request := range c.server.Requests() {
+		if unescaped, err := url.QueryUnescape(request); err == nil && strings.HasPrefix(request, "GET /api/v1/logs?") {
+			if !strings.Contains(unescaped, `target.id eq "00u1\" or target.id sw \""`) {
+				t.Errorf("expected the user ID to be escaped in the filter, got %s", unescaped)
+			}
+			return
+		}
+	}
+	t.Errorf("expected the System Log to be requested, got %v", c.server.Requests())
+}
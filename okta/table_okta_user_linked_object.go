@@ -0,0 +1,177 @@
+package okta
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	oktav4 "github.com/okta/okta-sdk-golang/v4/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOktaUserLinkedObject() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_user_linked_object",
+		Description: "Represents a linked object relationship of an Okta user, e.g. the manager or the subordinates of the user.",
+		List: &plugin.ListConfig{
+			Hydrate:    listOktaUserLinkedObjects,
+			KeyColumns: plugin.OptionalColumns([]string{"user_id", "relationship_name"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "ID of the user."},
+			{Name: "user_login", Type: proto.ColumnType_STRING, Description: "Login of the user."},
+			{Name: "relationship_name", Type: proto.ColumnType_STRING, Description: "Name of the relationship the linked user has with the user, e.g. manager if the linked user is the manager of the user."},
+			{Name: "linked_user_id", Type: proto.ColumnType_STRING, Description: "ID of the linked user."},
+
+			// Other Columns
+			{Name: "relationship_title", Type: proto.ColumnType_STRING, Description: "Display name of the relationship."},
+			{Name: "relationship_side", Type: proto.ColumnType_STRING, Description: "Side of the linked object definition the linked user is on. Can be one of PRIMARY, e.g. a manager, or ASSOCIATED, e.g. a subordinate."},
+			{Name: "primary_name", Type: proto.ColumnType_STRING, Description: "Name of the primary relationship of the linked object definition, e.g. manager."},
+			{Name: "associated_name", Type: proto.ColumnType_STRING, Description: "Name of the associated relationship of the linked object definition, e.g. subordinate."},
+			{Name: "linked_user_link", Type: proto.ColumnType_STRING, Description: "A link to the linked user."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("RelationshipName"), Description: titleDescription},
+		}),
+	}
+}
+
+type UserLinkedObject struct {
+	UserId            string
+	UserLogin         string
+	RelationshipName  string
+	RelationshipTitle string
+	RelationshipSide  string
+	PrimaryName       string
+	AssociatedName    string
+	LinkedUserId      string
+	LinkedUserLink    string
+}
+
+// userLinkedObjectRelationship is one side of a linked object definition.
+type userLinkedObjectRelationship struct {
+	Name           string
+	Title          string
+	Side           string
+	PrimaryName    string
+	AssociatedName string
+}
+
+//// LIST FUNCTION
+
+func listOktaUserLinkedObjects(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	// Only the user matching the user_id qual is fetched, rather than every
+	// user of the org
+	users, err := listUsersMatchingQuals(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_linked_object.listOktaUserLinkedObjects", "list_users_error", err)
+		return nil, err
+	}
+
+	client, err := ConnectV4(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_linked_object.listOktaUserLinkedObjects", "connect_error", err)
+		return nil, err
+	}
+
+	definitions, err := listCachedOktaLinkedObjectDefinitions(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_linked_object.listOktaUserLinkedObjects", "list_linked_object_definitions_error", err)
+		return nil, err
+	}
+
+	// Each definition is a pair of relationships, e.g. the manager of a
+	// subordinate is listed with the primary name and the subordinates of a
+	// manager with the associated name
+	var relationships []userLinkedObjectRelationship
+	for _, definition := range definitions {
+		if definition.Primary == nil || definition.Associated == nil {
+			continue
+		}
+		primaryName, associatedName := definition.Primary.GetName(), definition.Associated.GetName()
+		for _, relationship := range []userLinkedObjectRelationship{
+			{Name: primaryName, Title: definition.Primary.GetTitle(), Side: "PRIMARY", PrimaryName: primaryName, AssociatedName: associatedName},
+			{Name: associatedName, Title: definition.Associated.GetTitle(), Side: "ASSOCIATED", PrimaryName: primaryName, AssociatedName: associatedName},
+		} {
+			if d.EqualsQuals["relationship_name"] != nil && d.EqualsQualString("relationship_name") != relationship.Name {
+				continue
+			}
+			relationships = append(relationships, relationship)
+		}
+	}
+
+	err = forEachUserConcurrently(ctx, d, "okta_user_linked_object.listOktaUserLinkedObjects", users, func(user *okta.User) error {
+		return streamUserLinkedObjects(ctx, d, client, user, relationships)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// streamUserLinkedObjects streams the users linked to the user by each of the
+// relationships.
+func streamUserLinkedObjects(ctx context.Context, d *plugin.QueryData, client *oktav4.APIClient, user *okta.User, relationships []userLinkedObjectRelationship) error {
+	userLogin, _ := userLoginAndEmail(user)
+
+	for _, relationship := range relationships {
+		paginator := newPaginatorV4(d, "okta_user_linked_object.listOktaUserLinkedObjects", client.UserAPI.ListLinkedObjectsForUser(ctx, user.Id, relationship.Name).Execute)
+		err := paginator.StreamFunc(ctx, func(linkedObject map[string]interface{}) interface{} {
+			link := linkedObjectSelfLink(linkedObject)
+			return UserLinkedObject{
+				UserId:            user.Id,
+				UserLogin:         userLogin,
+				RelationshipName:  relationship.Name,
+				RelationshipTitle: relationship.Title,
+				RelationshipSide:  relationship.Side,
+				PrimaryName:       relationship.PrimaryName,
+				AssociatedName:    relationship.AssociatedName,
+				LinkedUserId:      linkedUserId(link),
+				LinkedUserLink:    link,
+			}
+		})
+		if err != nil {
+			// Users without a linked object for the relationship
+			if isNotFoundError(err) {
+				continue
+			}
+			return err
+		}
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+//// UTILITY FUNCTIONS
+
+// Linked objects are only returned as a link to the linked user, e.g.
+// {"_links": {"self": {"href": "https://example.okta.com/api/v1/users/00u1"}}}
+func linkedObjectSelfLink(linkedObject map[string]interface{}) string {
+	links, _ := linkedObject["_links"].(map[string]interface{})
+	self, _ := links["self"].(map[string]interface{})
+	href, _ := self["href"].(string)
+	return href
+}
+
+func linkedUserId(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return path.Base(u.Path)
+}
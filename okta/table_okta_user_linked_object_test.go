@@ -0,0 +1,42 @@
+package okta
+
+import (
+	"testing"
+)
+
+func TestOktaUserLinkedObjectList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user_linked_object"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// The definitions are listed once for every user
+	if n := c.server.countRequests("GET /api/v1/meta/schemas/user/linkedObjects"); n != 1 {
+		t.Errorf("expected the linked object definitions to be listed once, got %d requests", n)
+	}
+}
+
+func TestOktaUserLinkedObjectListByRelationship(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user_linked_object",
+		Columns: []string{"user_id", "relationship_name", "linked_user_id"},
+		Quals:   map[string]interface{}{"user_id": "00u2bobxxxxxxxxxxxx2", "relationship_name": "manager"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// The user is fetched by ID rather than listing every user
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 0 {
+		t.Errorf("expected the users not to be listed, got %v", c.server.Requests())
+	}
+	if n := c.server.countRequests("GET /api/v1/users/00u2bobxxxxxxxxxxxx2/linkedObjects/"); n != 1 {
+		t.Errorf("expected only the manager of the user to be listed, got %v", c.server.Requests())
+	}
+}
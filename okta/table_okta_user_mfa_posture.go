@@ -0,0 +1,629 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	oktav4 "github.com/okta/okta-sdk-golang/v4/okta"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Assurance levels required by sign-on rules, from weakest to strongest
+const (
+	assurance1FA               = "1FA"
+	assurance2FA               = "2FA"
+	assurancePhishingResistant = "PHISHING_RESISTANT"
+)
+
+var (
+	assuranceStrength = map[string]int{
+		assurance1FA:               1,
+		assurance2FA:               2,
+		assurancePhishingResistant: 3,
+	}
+
+	// Strength of the MFA method types, from weakest to strongest. Passwords
+	// aren't an MFA method type.
+	methodTypeStrength = map[string]int{
+		"security_question": 1,
+		"email":             2,
+		"sms":               3,
+		"voice":             3,
+		"otp":               4,
+		"totp":              4,
+		"hotp":              4,
+		"push":              5,
+		"duo":               5,
+		"idp":               5,
+		"signed_nonce":      6,
+		"webauthn":          6,
+		"cert":              6,
+	}
+
+	// Method types of the classic factor types, for orgs without authenticator
+	// enrollments
+	factorTypeMethodTypes = map[string]string{
+		"call":                "voice",
+		"email":               "email",
+		"push":                "push",
+		"question":            "security_question",
+		"signed_nonce":        "signed_nonce",
+		"sms":                 "sms",
+		"token":               "otp",
+		"token:hardware":      "otp",
+		"token:hotp":          "hotp",
+		"token:software:totp": "totp",
+		"u2f":                 "webauthn",
+		"web":                 "duo",
+		"webauthn":            "webauthn",
+	}
+)
+
+//// TABLE DEFINITION
+
+func tableOktaUserMfaPosture() *plugin.Table {
+	return &plugin.Table{
+		Name:        "okta_user_mfa_posture",
+		Description: "Summarizes the phishing-resistant MFA coverage of each Okta user, from their enrolled authenticators and the sign-on rules that apply to them.",
+		List: &plugin.ListConfig{
+			Hydrate: listOktaUserMfaPostures,
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "user_id", Require: plugin.Optional},
+				{Name: "user_name", Require: plugin.Optional},
+				{Name: "user_email", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			// Top Columns
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "ID of the user."},
+			{Name: "user_name", Type: proto.ColumnType_STRING, Description: "Login of the user."},
+			{Name: "user_email", Type: proto.ColumnType_STRING, Description: "Primary email address of the user."},
+			{Name: "has_phishing_resistant_factor", Type: proto.ColumnType_BOOL, Description: "True if the user has an active phishing-resistant authenticator, i.e. Okta FastPass, WebAuthn or a smart card. Null if unknown, i.e. the user has no other phishing-resistant authenticator and an Okta Verify enrollment that doesn't list its methods."},
+			{Name: "gap_reason", Type: proto.ColumnType_STRING, Description: "Why the user isn't fully covered by phishing-resistant MFA, or null if they are."},
+
+			// Other Columns
+			{Name: "user_status", Type: proto.ColumnType_STRING, Description: "Current status of the user."},
+			{Name: "weakest_enrolled_factor", Type: proto.ColumnType_STRING, Description: "Weakest MFA method type the user has enrolled, e.g. sms. Null if the user has no MFA factor."},
+			{Name: "strongest_required_assurance", Type: proto.ColumnType_STRING, Description: "Strongest assurance required by the sign-on rules that may apply to the user. Can be one of 1FA, 2FA or PHISHING_RESISTANT."},
+			{Name: "weakest_required_assurance", Type: proto.ColumnType_STRING, Description: "Weakest assurance required by the sign-on rules that may apply to the user. Can be one of 1FA, 2FA or PHISHING_RESISTANT."},
+			{Name: "enrollment_source", Type: proto.ColumnType_STRING, Description: "Where the enrollments of the user were read from, authenticator_enrollments for Identity Engine orgs or factors for Classic Engine orgs."},
+
+			// JSON Columns
+			{Name: "enrolled_method_types", Type: proto.ColumnType_JSON, Description: "Method types of the active MFA enrollments of the user, e.g. push and signed_nonce."},
+			{Name: "policy_requirements", Type: proto.ColumnType_JSON, Description: "Assurance required by every policy that applies to the user, with the rules that may apply to them."},
+
+			// Steampipe Columns
+			{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.FromField("UserName"), Description: titleDescription},
+		}),
+	}
+}
+
+type UserMfaPosture struct {
+	UserId                     string
+	UserName                   string
+	UserEmail                  string
+	UserStatus                 string
+	HasPhishingResistantFactor *bool
+	GapReason                  *string
+	WeakestEnrolledFactor      *string
+	StrongestRequiredAssurance *string
+	WeakestRequiredAssurance   *string
+	EnrollmentSource           string
+	EnrolledMethodTypes        []string
+	PolicyRequirements         []PolicyAssuranceRequirement
+}
+
+// enrolledMethodTypes are the method types of the active MFA enrollments of a
+// user, and where they were read from.
+type enrolledMethodTypes struct {
+	source            string
+	methodTypes       []string
+	phishingResistant *bool
+}
+
+// PolicyAssuranceRequirement is the weakest assurance a policy may require
+// from a user.
+type PolicyAssuranceRequirement struct {
+	PolicyId   string   `json:"policy_id"`
+	PolicyName string   `json:"policy_name"`
+	PolicyType string   `json:"policy_type"`
+	RuleNames  []string `json:"rule_names"`
+	Assurance  string   `json:"assurance"`
+}
+
+// mfaPosturePolicy is a sign-on policy, or one of its rules. Conditions are
+// kept raw so that rules with conditions that can't be evaluated offline, e.g.
+// network zones or device platforms, are recognized.
+type mfaPosturePolicy struct {
+	Id         string                     `json:"id,omitempty"`
+	Name       string                     `json:"name,omitempty"`
+	Type       string                     `json:"type,omitempty"`
+	Status     string                     `json:"status,omitempty"`
+	Priority   int64                      `json:"priority,omitempty"`
+	Conditions map[string]json.RawMessage `json:"conditions,omitempty"`
+	Actions    *mfaPostureRuleActions     `json:"actions,omitempty"`
+	Rules      []*mfaPosturePolicy        `json:"-"`
+}
+
+type mfaPostureRuleActions struct {
+	// Okta sign-on policy rules
+	Signon *struct {
+		Access        string `json:"access,omitempty"`
+		RequireFactor bool   `json:"requireFactor,omitempty"`
+	} `json:"signon,omitempty"`
+
+	// Authentication policy rules
+	AppSignOn *struct {
+		Access             string `json:"access,omitempty"`
+		VerificationMethod *struct {
+			Type        string                              `json:"type,omitempty"`
+			FactorMode  string                              `json:"factorMode,omitempty"`
+			Constraints []map[string]map[string]interface{} `json:"constraints,omitempty"`
+		} `json:"verificationMethod,omitempty"`
+	} `json:"appSignOn,omitempty"`
+}
+
+type includeExcludeCondition struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+type peopleCondition struct {
+	Users  *includeExcludeCondition `json:"users,omitempty"`
+	Groups *includeExcludeCondition `json:"groups,omitempty"`
+}
+
+//// LIST FUNCTION
+
+func listOktaUserMfaPostures(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+
+	users, err := listUsersMatchingQuals(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_mfa_posture.listOktaUserMfaPostures", "list_users_error", err)
+		return nil, err
+	}
+
+	policies, err := listCachedOktaMfaPosturePolicies(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_mfa_posture.listOktaUserMfaPostures", "list_policies_error", err)
+		return nil, err
+	}
+
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_mfa_posture.listOktaUserMfaPostures", "connect_error", err)
+		return nil, err
+	}
+	clientV4, err := ConnectV4(ctx, d)
+	if err != nil {
+		logger.Error("okta_user_mfa_posture.listOktaUserMfaPostures", "connect_error", err)
+		return nil, err
+	}
+
+	listUserGroups, err := newUserGroupsLookup(ctx, d, len(users))
+	if err != nil {
+		logger.Error("okta_user_mfa_posture.listOktaUserMfaPostures", "list_user_groups_error", err)
+		return nil, err
+	}
+
+	err = forEachUserConcurrently(ctx, d, "okta_user_mfa_posture.listOktaUserMfaPostures", users, func(user *okta.User) error {
+		enrolled, err := listEnrolledMethodTypes(ctx, d, client, clientV4, user.Id)
+		if err != nil {
+			// The user may have been deleted since it was listed
+			if isNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+
+		groups, err := listUserGroups(user.Id)
+		if err != nil {
+			return err
+		}
+		groupIds := make([]string, len(groups))
+		for i, group := range groups {
+			groupIds[i] = group.Id
+		}
+
+		d.StreamListItem(ctx, userMfaPosture(user, enrolled, userPolicyRequirements(policies, user, groupIds)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listCachedOktaMfaPosturePolicies returns the active authentication policies
+// of the org, which are only available in Identity Engine orgs, and its active
+// Okta sign-on policies, with their active rules, in priority order.
+func listCachedOktaMfaPosturePolicies(ctx context.Context, d *plugin.QueryData) ([]*mfaPosturePolicy, error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return getCachedList(ctx, d, "listCachedOktaMfaPosturePolicies", func() ([]*mfaPosturePolicy, error) {
+		var policies []*mfaPosturePolicy
+		for _, policyType := range []string{"ACCESS_POLICY", "OKTA_SIGN_ON"} {
+			paginator := newPaginatorV2(d, "listCachedOktaMfaPosturePolicies", func() ([]*mfaPosturePolicy, *okta.Response, error) {
+				return listMfaPosturePolicies(ctx, client, "/api/v1/policies"+(&query.Params{Type: policyType}).String())
+			})
+			items, err := paginator.All(ctx)
+			// Classic Engine orgs reject the authentication policy type
+			if err != nil && !isNotFoundError(err) && !isFeatureNotAvailableError(err) && !(policyType == "ACCESS_POLICY" && isUnsupportedPolicyTypeError(err)) {
+				return nil, err
+			}
+			policies = append(policies, activeMfaPosturePolicies(items)...)
+		}
+
+		for _, policy := range policies {
+			paginator := newPaginatorV2(d, "listCachedOktaMfaPosturePolicies.listPolicyRules", func() ([]*mfaPosturePolicy, *okta.Response, error) {
+				return listMfaPosturePolicies(ctx, client, fmt.Sprintf("/api/v1/policies/%s/rules", policy.Id))
+			})
+			rules, err := paginator.All(ctx)
+			if err != nil && !isNotFoundError(err) {
+				return nil, err
+			}
+			policy.Rules = activeMfaPosturePolicies(rules)
+		}
+		return policies, nil
+	})
+}
+
+// listMfaPosturePolicies lists policies or policy rules, whose actions and
+// conditions the SDK types don't fully cover.
+func listMfaPosturePolicies(ctx context.Context, client *okta.Client, url string) ([]*mfaPosturePolicy, *okta.Response, error) {
+	requestExecutor := client.GetRequestExecutor()
+	req, err := requestExecutor.WithAccept("application/json").WithContentType("application/json").NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var policies []*mfaPosturePolicy
+
+	resp, err := requestExecutor.Do(ctx, req, &policies)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return policies, resp, nil
+}
+
+// listEnrolledMethodTypes returns the method types of the active MFA
+// enrollments of the user, read from the authenticator enrollments of
+// Identity Engine orgs or else from the factors of the user.
+func listEnrolledMethodTypes(ctx context.Context, d *plugin.QueryData, client *okta.Client, clientV4 *oktav4.APIClient, userId string) (*enrolledMethodTypes, error) {
+	enrolled := &enrolledMethodTypes{}
+	addMethodType := func(methodType string) {
+		if methodTypeStrength[methodType] > 0 && !slices.Contains(enrolled.methodTypes, methodType) {
+			enrolled.methodTypes = append(enrolled.methodTypes, methodType)
+		}
+	}
+
+	paginator := newPaginatorV2(d, "okta_user_mfa_posture.listAuthenticatorEnrollments", func() ([]*AuthenticatorEnrollment, *okta.Response, error) {
+		return listAuthenticatorEnrollments(ctx, client, userId)
+	})
+	enrollments, err := paginator.All(ctx)
+	if err == nil {
+		enrolled.source = "authenticator_enrollments"
+		phishingResistant, unknown := false, false
+		for _, enrollment := range enrollments {
+			if enrollment.Status != "ACTIVE" {
+				continue
+			}
+			for _, methodType := range enrollmentMethodTypes(enrollment) {
+				addMethodType(methodType)
+			}
+			if p := enrollmentPhishingResistant(enrollment); p == nil {
+				unknown = true
+			} else if *p {
+				phishingResistant = true
+			}
+		}
+		if phishingResistant || !unknown {
+			enrolled.phishingResistant = &phishingResistant
+		}
+		return enrolled, nil
+	}
+	if !isNotFoundError(err) && !isFeatureNotAvailableError(err) {
+		return nil, err
+	}
+
+	factorsPaginator := newPaginatorV4(d, "okta_user_mfa_posture.listFactors", clientV4.UserFactorAPI.ListFactors(ctx, userId).Execute)
+	factors, err := factorsPaginator.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, factor := range factors {
+		if factor.GetActualInstance() == nil {
+			continue
+		}
+		details := getFactorDetails(factor.GetActualInstance())
+		if details.GetStatus() == "ACTIVE" {
+			addMethodType(factorTypeMethodTypes[details.GetFactorType()])
+		}
+	}
+	enrolled.source = "factors"
+	phishingResistant := slices.ContainsFunc(enrolled.methodTypes, func(methodType string) bool {
+		return slices.Contains(phishingResistantMethodTypes, methodType)
+	})
+	enrolled.phishingResistant = &phishingResistant
+	return enrolled, nil
+}
+
+//// UTILITY FUNCTIONS
+
+func userMfaPosture(user *okta.User, enrolled *enrolledMethodTypes, requirements []PolicyAssuranceRequirement) UserMfaPosture {
+	userName, userEmail := userLoginAndEmail(user)
+	posture := UserMfaPosture{
+		UserId:                     user.Id,
+		UserName:                   userName,
+		UserEmail:                  userEmail,
+		UserStatus:                 user.Status,
+		HasPhishingResistantFactor: enrolled.phishingResistant,
+		EnrollmentSource:           enrolled.source,
+		EnrolledMethodTypes:        enrolled.methodTypes,
+		PolicyRequirements:         requirements,
+	}
+
+	for _, methodType := range enrolled.methodTypes {
+		if posture.WeakestEnrolledFactor == nil || methodTypeStrength[methodType] < methodTypeStrength[*posture.WeakestEnrolledFactor] {
+			posture.WeakestEnrolledFactor = &methodType
+		}
+	}
+
+	var weakPolicies []string
+	for _, requirement := range requirements {
+		assurance := requirement.Assurance
+		if posture.StrongestRequiredAssurance == nil || assuranceStrength[assurance] > assuranceStrength[*posture.StrongestRequiredAssurance] {
+			posture.StrongestRequiredAssurance = &assurance
+		}
+		if posture.WeakestRequiredAssurance == nil || assuranceStrength[assurance] < assuranceStrength[*posture.WeakestRequiredAssurance] {
+			posture.WeakestRequiredAssurance = &assurance
+		}
+		if assurance != assurancePhishingResistant {
+			weakPolicies = append(weakPolicies, fmt.Sprintf("%s (%s)", requirement.PolicyName, assurance))
+		}
+	}
+
+	var gapReason string
+	switch {
+	case len(enrolled.methodTypes) == 0:
+		gapReason = "No MFA factor enrolled"
+	case posture.HasPhishingResistantFactor == nil:
+		gapReason = "Unknown whether the Okta Verify enrollments include FastPass"
+	case !*posture.HasPhishingResistantFactor:
+		gapReason = "No phishing-resistant factor enrolled"
+	case len(requirements) == 0:
+		gapReason = "No sign-on policy rule applies to the user"
+	case len(weakPolicies) > 0:
+		gapReason = "Policies don't require phishing resistance: " + strings.Join(weakPolicies, ", ")
+	}
+	if gapReason != "" {
+		posture.GapReason = &gapReason
+	}
+
+	return posture
+}
+
+// userPolicyRequirements evaluates the policies that apply to the user. Every
+// authentication policy may apply, depending on the app signed in to, whereas
+// only the first Okta sign-on policy matching the user applies.
+func userPolicyRequirements(policies []*mfaPosturePolicy, user *okta.User, groupIds []string) []PolicyAssuranceRequirement {
+	var requirements []PolicyAssuranceRequirement
+	signOnMatched := false
+	for _, policy := range policies {
+		// Only the first Okta sign-on policy matching the user applies
+		if policy.Type == "OKTA_SIGN_ON" && signOnMatched {
+			continue
+		}
+
+		matches, _ := matchesMfaPostureConditions(policy.Conditions, user, groupIds)
+		if !matches {
+			continue
+		}
+
+		if requirement, ok := policyRequirement(policy, user, groupIds); ok {
+			requirements = append(requirements, requirement)
+		}
+
+		if policy.Type == "OKTA_SIGN_ON" {
+			signOnMatched = true
+		}
+	}
+	return requirements
+}
+
+// policyRequirement returns the weakest assurance required by the rules of
+// the policy that may apply to the user. Rules are evaluated in priority order
+// until a rule certainly applies, i.e. its people conditions match the user
+// and it has no conditions that can only be evaluated at sign-in. Rules
+// denying access are ignored.
+func policyRequirement(policy *mfaPosturePolicy, user *okta.User, groupIds []string) (PolicyAssuranceRequirement, bool) {
+	requirement := PolicyAssuranceRequirement{
+		PolicyId:   policy.Id,
+		PolicyName: policy.Name,
+		PolicyType: policy.Type,
+	}
+
+	for _, rule := range policy.Rules {
+		matches, certain := matchesMfaPostureConditions(rule.Conditions, user, groupIds)
+		if !matches {
+			continue
+		}
+
+		if assurance, ok := ruleAssurance(rule); ok {
+			requirement.RuleNames = append(requirement.RuleNames, rule.Name)
+			if requirement.Assurance == "" || assuranceStrength[assurance] < assuranceStrength[requirement.Assurance] {
+				requirement.Assurance = assurance
+			}
+		}
+
+		if certain {
+			break
+		}
+	}
+
+	return requirement, requirement.Assurance != ""
+}
+
+// ruleAssurance returns the assurance required by a rule allowing access.
+func ruleAssurance(rule *mfaPosturePolicy) (string, bool) {
+	if rule.Actions == nil {
+		return "", false
+	}
+
+	if signon := rule.Actions.Signon; signon != nil {
+		if signon.Access == "DENY" {
+			return "", false
+		}
+		if signon.RequireFactor {
+			return assurance2FA, true
+		}
+		return assurance1FA, true
+	}
+
+	if appSignOn := rule.Actions.AppSignOn; appSignOn != nil {
+		if appSignOn.Access == "DENY" {
+			return "", false
+		}
+		method := appSignOn.VerificationMethod
+		if method == nil {
+			return assurance1FA, true
+		}
+		if method.Type != "ASSURANCE" {
+			return assurance2FA, true
+		}
+		// Constraints are alternatives, so phishing resistance is only
+		// required if every alternative requires it
+		phishingResistant := len(method.Constraints) > 0
+		for _, constraint := range method.Constraints {
+			if constraint["possession"]["phishingResistant"] != "REQUIRED" {
+				phishingResistant = false
+			}
+		}
+		switch {
+		case phishingResistant:
+			return assurancePhishingResistant, true
+		case method.FactorMode == "2FA":
+			return assurance2FA, true
+		default:
+			return assurance1FA, true
+		}
+	}
+
+	return "", false
+}
+
+// matchesMfaPostureConditions returns whether the people and user type
+// conditions match the user, and whether the conditions certainly match, i.e.
+// there are no other conditions that can only be evaluated at sign-in.
+func matchesMfaPostureConditions(conditions map[string]json.RawMessage, user *okta.User, groupIds []string) (bool, bool) {
+	certain := true
+	for name, raw := range conditions {
+		switch name {
+		case "people":
+			var people peopleCondition
+			if err := json.Unmarshal(raw, &people); err != nil {
+				return false, false
+			}
+			if !matchesIncludeExclude(people.Users, []string{user.Id}) || !matchesIncludeExclude(people.Groups, groupIds) {
+				return false, false
+			}
+		case "userType":
+			var userType includeExcludeCondition
+			if err := json.Unmarshal(raw, &userType); err != nil {
+				return false, false
+			}
+			var userTypeIds []string
+			if user.Type != nil {
+				userTypeIds = []string{user.Type.Id}
+			}
+			if !matchesIncludeExclude(&userType, userTypeIds) {
+				return false, false
+			}
+		case "network":
+			var network struct {
+				Connection string `json:"connection"`
+			}
+			if json.Unmarshal(raw, &network) != nil || (network.Connection != "" && network.Connection != "ANYWHERE") {
+				certain = false
+			}
+		case "riskScore":
+			var riskScore struct {
+				Level string `json:"level"`
+			}
+			if json.Unmarshal(raw, &riskScore) != nil || (riskScore.Level != "" && riskScore.Level != "ANY") {
+				certain = false
+			}
+		default:
+			if value := strings.TrimSpace(string(raw)); value != "null" && value != "{}" {
+				certain = false
+			}
+		}
+	}
+	return true, certain
+}
+
+// matchesIncludeExclude returns true if none of the IDs are excluded, and one
+// of them is included or the condition includes everyone.
+func matchesIncludeExclude(condition *includeExcludeCondition, ids []string) bool {
+	if condition == nil {
+		return true
+	}
+	for _, id := range ids {
+		if slices.Contains(condition.Exclude, id) {
+			return false
+		}
+	}
+	if len(condition.Include) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if slices.Contains(condition.Include, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func activeMfaPosturePolicies(policies []*mfaPosturePolicy) []*mfaPosturePolicy {
+	var active []*mfaPosturePolicy
+	for _, policy := range policies {
+		if policy.Status == "ACTIVE" {
+			active = append(active, policy)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Priority < active[j].Priority
+	})
+	return active
+}
+
+// isFeatureNotAvailableError returns true if the endpoint isn't available in
+// the org, e.g. the Identity Engine endpoints of Classic Engine orgs, which
+// respond with E0000015 rather than a 404. Other 403 errors, e.g. a missing
+// scope, aren't ignored.
+func isFeatureNotAvailableError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && e.ErrorCode == "E0000015"
+}
+
+// isUnsupportedPolicyTypeError returns true if the org rejected the type of
+// policies listed, as Classic Engine orgs do for authentication policies.
+func isUnsupportedPolicyTypeError(err error) bool {
+	e := parseOktaError(err)
+	return e != nil && e.StatusCode == 400 && e.ErrorCode == "E0000001"
+}
@@ -0,0 +1,70 @@
+package okta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOktaUserMfaPostureList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user_mfa_posture"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaUserMfaPostureListGroupsIndex(t *testing.T) {
+	c := newTestConnection(t, `user_groups_index_threshold = 1`)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user_mfa_posture",
+		Columns: []string{"user_id", "has_phishing_resistant_factor", "gap_reason", "policy_requirements"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	if n := c.server.countRequests("GET /api/v1/users/00u1alicexxxxxxxxxx1/groups"); n != 0 {
+		t.Errorf("expected the groups of the users to be read from the index, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserMfaPostureListClassicEngine(t *testing.T) {
+	c := newTestConnection(t)
+
+	// Classic Engine orgs don't have authenticator enrollments
+	c.server.fail("/api/v1/users/00u3carolxxxxxxxxxx3/authenticator-enrollments", 403, "E0000015")
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user_mfa_posture",
+		Columns: []string{"user_id", "enrollment_source", "enrolled_method_types", "has_phishing_resistant_factor", "policy_requirements"},
+		Quals:   map[string]interface{}{"user_id": "00u3carolxxxxxxxxxx3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaUserMfaPostureListForbidden(t *testing.T) {
+	for _, path := range []string{"/api/v1/users/00u3carolxxxxxxxxxx3/authenticator-enrollments", "/api/v1/policies"} {
+		t.Run(path, func(t *testing.T) {
+			c := newTestConnection(t)
+
+			// A token without the required scope isn't a Classic Engine org
+			c.server.fail(path, 403, "E0000006")
+
+			_, err := c.query(t, testQuery{
+				Table:   "okta_user_mfa_posture",
+				Columns: []string{"user_id", "enrollment_source"},
+				Quals:   map[string]interface{}{"user_id": "00u3carolxxxxxxxxxx3"},
+			})
+			if err == nil || !strings.Contains(err.Error(), "E0000006") {
+				t.Errorf("expected the forbidden error to be returned, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package okta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOktaUserList(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+
+	// 3 users served 2 per page
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 2 {
+		t.Errorf("expected the users to be listed in 2 pages, got %d requests", n)
+	}
+}
+
+func TestOktaUserGet(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user",
+		Columns: []string{"id", "login", "email", "status", "user_groups", "assigned_roles"},
+		Quals:   map[string]interface{}{"id": "00u1alicexxxxxxxxxx1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertGolden(t, rows)
+}
+
+func TestOktaUserGetNotFound(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table: "okta_user",
+		Quals: map[string]interface{}{"id": "00u9missingxxxxxxxx9"},
+	})
+	if err != nil {
+		t.Fatalf("expected the 404 to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
+
+func TestOktaUserListRateLimited(t *testing.T) {
+	c := newTestConnection(t)
+	c.server.rateLimit("/api/v1/users", 1)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user", Columns: []string{"id", "login"}})
+	if err != nil {
+		t.Fatalf("expected the 429 to be retried, got %v", err)
+	}
+	c.assertGolden(t, rows)
+
+	// The rate limited first page is requested again
+	if n := c.server.countRequests("GET /api/v1/users?"); n != 3 {
+		t.Errorf("expected the first page to be retried, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserListLimit(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user", Columns: []string{"id"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("expected 1 row, got %d", len(rows))
+	}
+	// The limit is pushed down as the page size
+	if n := c.server.countRequests("GET /api/v1/users?limit=1"); n != 1 {
+		t.Errorf("expected a single request for a page of 1 user, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserListGroupsIndex(t *testing.T) {
+	c := newTestConnection(t, `user_groups_index_threshold = 1`)
+
+	rows, err := c.query(t, testQuery{Table: "okta_user", Columns: []string{"id", "user_groups"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Errorf("expected 3 rows, got %d", len(rows))
+	}
+
+	// The index is built once more users than the threshold have been listed
+	if n := c.server.countRequests("GET /api/v1/groups/00g2engineeringxxxx2/users"); n != 1 {
+		t.Errorf("expected the members of the groups to be listed once, got %v", c.server.Requests())
+	}
+	if n := c.server.countRequests("GET /api/v1/users/00u2bobxxxxxxxxxxxx2/groups"); n != 0 {
+		t.Errorf("expected the groups of the second user to be read from the index, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserGetGroupsAfterIndex(t *testing.T) {
+	c := newTestConnection(t, `user_groups_index_threshold = 1`)
+
+	if _, err := c.query(t, testQuery{Table: "okta_user", Columns: []string{"id", "user_groups"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cached index only serves the rows of the listing that built it
+	if _, err := c.query(t, testQuery{
+		Table:   "okta_user",
+		Columns: []string{"id", "user_groups"},
+		Quals:   map[string]interface{}{"id": "00u2bobxxxxxxxxxxxx2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.server.countRequests("GET /api/v1/users/00u2bobxxxxxxxxxxxx2/groups"); n != 1 {
+		t.Errorf("expected the groups of the user to be listed, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserListGroupsBelowThreshold(t *testing.T) {
+	c := newTestConnection(t)
+
+	rows, err := c.query(t, testQuery{
+		Table:   "okta_user",
+		Columns: []string{"id", "user_groups"},
+		Quals:   map[string]interface{}{"status": "ACTIVE"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) == 0 {
+		t.Errorf("expected rows")
+	}
+
+	// A few users are cheaper to look up one by one than building the index
+	if n := c.server.countRequests("GET /api/v1/groups"); n != 0 {
+		t.Errorf("expected the user groups index not to be built, got %v", c.server.Requests())
+	}
+}
+
+func TestOktaUserListIncremental(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestConnection(t, fmt.Sprintf("incremental_sync_state_dir = %q", dir))
+	path := filepath.Join(dir, c.name+".json")
+
+	query := testQuery{
+		Table:   "okta_user",
+		Columns: []string{"id"},
+		Quals:   map[string]interface{}{"incremental": true},
+	}
+	ids := func(rows []map[string]interface{}) string {
+		var ids []string
+		for _, row := range rows {
+			ids = append(ids, row["id"].(string))
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	// The state of the first sync is pending, so another scan right after it
+	// returns the same rows
+	for i := 0; i < 2; i++ {
+		rows, err := c.query(t, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(rows); got != "00u1alicexxxxxxxxxx1,00u2bobxxxxxxxxxxxx2,00u3carolxxxxxxxxxx3" {
+			t.Errorf("scan %d: expected every user, got %s", i, got)
+		}
+	}
+	for _, request := range c.server.Requests() {
+		if strings.Contains(request, "filter=") {
+			t.Errorf("expected the users to be listed without a filter before the state is committed, got %s", request)
+		}
+	}
+
+	var states map[string]syncState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		t.Fatal(err)
+	}
+	pending := states["okta_user"].Pending
+	if pending == nil || pending.LastUpdated == nil || len(pending.SyncedVersions) != 0 {
+		t.Fatalf("expected a pending state without synced versions, got %s", data)
+	}
+
+	// A committed state whose skew window includes alice, who was already synced
+	mark := time.Date(2024, 3, 2, 10, 3, 0, 0, time.UTC)
+	lastSynced := time.Now().Add(-2 * syncStateCommitDelay)
+	states["okta_user"] = syncState{
+		LastUpdated: &mark,
+		Pending: &syncState{
+			LastUpdated:    &mark,
+			LastSynced:     &lastSynced,
+			SyncedVersions: map[string]string{"00u1alicexxxxxxxxxx1": "2024-03-02T10:00:00Z"},
+		},
+	}
+	data, err = json.Marshal(states)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := c.query(t, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(rows); got != "00u2bobxxxxxxxxxxxx2,00u3carolxxxxxxxxxx3" {
+		t.Errorf("expected the users not synced yet, got %s", got)
+	}
+	filter := "filter=" + url.QueryEscape(`lastUpdated ge "2024-03-02T09:58:00.000Z"`)
+	found := false
+	for _, request := range c.server.Requests() {
+		found = found || strings.Contains(request, filter)
+	}
+	if !found {
+		t.Errorf("expected the users updated within the skew window of the mark to be listed, got %v", c.server.Requests())
+	}
+}
@@ -2,7 +2,6 @@ package okta
 
 import (
 	"context"
-	"strings"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
@@ -18,9 +17,9 @@ func tableOktaUserType() *plugin.Table {
 		Name:        "okta_user_type",
 		Description: "Represents an Okta user account.",
 		Get: &plugin.GetConfig{
-			Hydrate:           getOktaUserType,
-			KeyColumns:        plugin.SingleColumn("id"),
-			ShouldIgnoreError: isNotFoundError([]string{"Not found"}),
+			Hydrate:      getOktaUserType,
+			KeyColumns:   plugin.SingleColumn("id"),
+			IgnoreConfig: &plugin.IgnoreConfig{ShouldIgnoreErrorFunc: shouldIgnoreErrors(isNotFoundError)},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listOktaUserTypes,
@@ -56,43 +55,17 @@ func listOktaUserTypes(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydra
 		return nil, err
 	}
 
-	userTypes, resp, err := client.UserType.ListUserTypes(ctx)
-	if err != nil {
-		logger.Error("listOktaUserTypes", "list_user_types_error", err)
-		if strings.Contains(err.Error(), "Not found") {
+	paginator := newPaginatorV2(d, "listOktaUserTypes", func() ([]*okta.UserType, *okta.Response, error) {
+		return client.UserType.ListUserTypes(ctx)
+	})
+	if err := paginator.Stream(ctx); err != nil {
+		if isNotFoundError(err) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	for _, userType := range userTypes {
-		d.StreamListItem(ctx, userType)
-
-		// Context can be cancelled due to manual cancellation or the limit has been hit
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
-		}
-	}
-
-	// paging
-	for resp.HasNextPage() {
-		var nextUserTypeSet []*okta.UserType
-		resp, err = resp.Next(ctx, &nextUserTypeSet)
-		if err != nil {
-			logger.Error("listOktaUserTypes", "list_user_types_paging_error", err)
-			return nil, err
-		}
-		for _, user := range nextUserTypeSet {
-			d.StreamListItem(ctx, user)
-
-			// Context can be cancelled due to manual cancellation or the limit has been hit
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
-		}
-	}
-
-	return nil, err
+	return nil, nil
 }
 
 //// HYDRATE FUNCTIONS
@@ -113,10 +86,11 @@ func getOktaUserType(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 		return nil, err
 	}
 
-	userType, _, err := client.UserType.GetUserType(ctx, userTypeId)
+	userType, resp, err := client.UserType.GetUserType(ctx, userTypeId)
+	err = withResponseStatus(err, resp)
 	if err != nil {
 		logger.Error("getOktaUserType", "get_user_type_error", err)
-		return nil, err
+		return nil, handleOktaError(d, err)
 	}
 
 	return userType, nil
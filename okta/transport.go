@@ -0,0 +1,244 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/context_key"
+)
+
+// Same as the default connection timeout of the v2 SDK HTTP client
+const defaultConnectionTimeout = 60 * time.Second
+
+// Path segments that look like Okta resource IDs (e.g. 00u1e63jiqAHskqSd5d7) are
+// replaced so that rate limits are tracked per endpoint rather than per resource.
+var resourceIdPattern = regexp.MustCompile(`^[0-9a-zA-Z]{20}$`)
+
+// connectionStats holds information observed on the responses of every SDK client
+// of a connection. It is kept outside of the connection cache so that it is shared
+// by the v2, v4 and v5 clients for the lifetime of the plugin process.
+type connectionStats struct {
+	mutex         sync.RWMutex
+	rateLimits    map[string]*rateLimitInfo
+	pagination    map[string]*paginationInfo
+	grantedScopes []string
+}
+
+type rateLimitInfo struct {
+	Endpoint  string     `json:"endpoint"`
+	Limit     int64      `json:"limit"`
+	Remaining int64      `json:"remaining"`
+	Reset     *time.Time `json:"reset,omitempty"`
+	LastSeen  time.Time  `json:"last_seen"`
+}
+
+// map of connection name to *connectionStats
+var connectionStatsMap sync.Map
+
+func getConnectionStats(d *plugin.QueryData) *connectionStats {
+	stats, _ := connectionStatsMap.LoadOrStore(d.Connection.Name, &connectionStats{
+		rateLimits: map[string]*rateLimitInfo{},
+		pagination: map[string]*paginationInfo{},
+	})
+	return stats.(*connectionStats)
+}
+
+// RateLimits returns the most recent rate limit headers seen per endpoint, sorted by endpoint.
+func (s *connectionStats) RateLimits() []rateLimitInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rateLimits := make([]rateLimitInfo, 0, len(s.rateLimits))
+	for _, info := range s.rateLimits {
+		rateLimits = append(rateLimits, *info)
+	}
+	sort.Slice(rateLimits, func(i, j int) bool {
+		return rateLimits[i].Endpoint < rateLimits[j].Endpoint
+	})
+	return rateLimits
+}
+
+// GrantedScopes returns the scopes granted in the last OAuth access token issued to the connection.
+func (s *connectionStats) GrantedScopes() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.grantedScopes
+}
+
+func (s *connectionStats) recordRateLimit(endpoint string, header http.Header) {
+	limit, err := strconv.ParseInt(header.Get("X-Rate-Limit-Limit"), 10, 64)
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.ParseInt(header.Get("X-Rate-Limit-Remaining"), 10, 64)
+
+	info := &rateLimitInfo{
+		Endpoint:  endpoint,
+		Limit:     limit,
+		Remaining: remaining,
+		LastSeen:  time.Now(),
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		resetTime := time.Unix(reset, 0)
+		info.Reset = &resetTime
+	}
+
+	s.mutex.Lock()
+	s.rateLimits[endpoint] = info
+	s.mutex.Unlock()
+}
+
+// waitForRateLimit blocks until a request to the endpoint can be made without
+// using more than rateLimitPercent of the endpoint's rate limit bucket.
+//
+// Each admitted request decrements the remaining count locally, so concurrent
+// hydrate calls are throttled before the bucket is exhausted rather than after
+// Okta starts returning 429 responses.
+func (s *connectionStats) waitForRateLimit(ctx context.Context, endpoint string, rateLimitPercent int64) error {
+	for {
+		s.mutex.Lock()
+		info, ok := s.rateLimits[endpoint]
+		if !ok || info.Reset == nil {
+			s.mutex.Unlock()
+			return nil
+		}
+
+		// The bucket has been replenished since the headers were seen
+		if time.Now().After(*info.Reset) {
+			info.Remaining = info.Limit
+			info.Reset = nil
+			s.mutex.Unlock()
+			return nil
+		}
+
+		// Number of requests left for other integrations using the same org
+		headroom := info.Limit - info.Limit*rateLimitPercent/100
+		if info.Remaining > headroom {
+			info.Remaining--
+			s.mutex.Unlock()
+			return nil
+		}
+
+		wait := time.Until(*info.Reset)
+		s.mutex.Unlock()
+
+		requestLogger(ctx).Info("waitForRateLimit", "endpoint", endpoint, "limit", info.Limit, "rate_limit_percent", rateLimitPercent, "wait", wait.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (s *connectionStats) recordGrantedScopes(body []byte) {
+	var token struct {
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.Scope == "" {
+		return
+	}
+
+	s.mutex.Lock()
+	s.grantedScopes = strings.Fields(token.Scope)
+	s.mutex.Unlock()
+}
+
+// oktaTransport throttles requests according to the rate limit headers of
+// previous responses, and records rate limit headers and granted OAuth scopes
+// before handing the response back to the SDK.
+type oktaTransport struct {
+	base             http.RoundTripper
+	stats            *connectionStats
+	rateLimitPercent int64
+}
+
+func (t *oktaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := rateLimitEndpoint(req)
+	if err := t.stats.waitForRateLimit(req.Context(), endpoint, t.rateLimitPercent); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	t.stats.recordRateLimit(endpoint, resp.Header)
+
+	if strings.HasSuffix(req.URL.Path, "/v1/token") && resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewBuffer(body))
+		t.stats.recordGrantedScopes(body)
+	}
+
+	return resp, nil
+}
+
+// newOktaHttpClient returns the HTTP client shared by all SDK clients of a connection.
+func newOktaHttpClient(d *plugin.QueryData) *http.Client {
+	rateLimitPercent := int64(100)
+	if percent := GetConfig(d.Connection).RateLimitPercent; percent != nil && *percent > 0 && *percent <= 100 {
+		rateLimitPercent = *percent
+	}
+
+	// The timeout is set on the transport rather than the client so that time
+	// spent waiting for a rate limit bucket to reset isn't counted against it
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = defaultConnectionTimeout
+
+	// The cassette_mode argument is validated when the SDK clients are created
+	var transport http.RoundTripper = base
+	if mode, _ := cassetteMode(d); mode != "" {
+		transport = &cassetteTransport{
+			base: base,
+			mode: mode,
+			dir:  cassetteDir(d),
+		}
+	}
+
+	return &http.Client{
+		Transport: &oktaTransport{
+			base:             transport,
+			stats:            getConnectionStats(d),
+			rateLimitPercent: rateLimitPercent,
+		},
+	}
+}
+
+// requestLogger returns the plugin logger of the context of a request. The SDKs
+// request OAuth access tokens without the query context, so the default logger
+// is returned for those.
+func requestLogger(ctx context.Context) hclog.Logger {
+	if _, ok := ctx.Value(context_key.Logger).(hclog.Logger); ok {
+		return plugin.Logger(ctx)
+	}
+	return hclog.Default()
+}
+
+// rateLimitEndpoint builds the endpoint key used to group rate limits, e.g.
+// "GET /api/v1/users/{id}/groups".
+func rateLimitEndpoint(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if resourceIdPattern.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
@@ -0,0 +1,162 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	// Default maximum number of users whose rows are listed in parallel
+	defaultUserFanoutConcurrency = 10
+
+	// Number of users after which the progress of org-wide listings is logged
+	userProgressInterval = 500
+)
+
+// listUsersMatchingQuals returns the users of tables with one or more rows per
+// user. Users are fetched by ID if user_id is given, searched by login or
+// email if user_name or user_email is given, or else every user of the org is
+// listed from the list cache.
+func listUsersMatchingQuals(ctx context.Context, d *plugin.QueryData) ([]*okta.User, error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*okta.User
+	switch {
+	case d.EqualsQuals["user_id"] != nil:
+		userIds := []string{d.EqualsQualString("user_id")}
+		if listValue := d.EqualsQuals["user_id"].GetListValue(); listValue != nil {
+			userIds = types.StringValueSlice(getListValues(listValue))
+		}
+		for _, userId := range userIds {
+			if userId == "" {
+				continue
+			}
+			user, resp, err := client.User.GetUser(ctx, userId)
+			err = withResponseStatus(err, resp)
+			if err != nil {
+				if isNotFoundError(err) {
+					continue
+				}
+				return nil, handleOktaError(d, err)
+			}
+			users = append(users, user)
+		}
+
+	case d.EqualsQuals["user_name"] != nil || d.EqualsQuals["user_email"] != nil:
+		var filter []string
+		if d.EqualsQuals["user_name"] != nil {
+			filter = append(filter, fmt.Sprintf("profile.login eq \"%s\"", escapeFilterValue(d.EqualsQualString("user_name"))))
+		}
+		if d.EqualsQuals["user_email"] != nil {
+			filter = append(filter, fmt.Sprintf("profile.email eq \"%s\"", escapeFilterValue(d.EqualsQualString("user_email"))))
+		}
+		input := query.Params{Filter: strings.Join(filter, " and "), Limit: 200}
+		paginator := newPaginatorV2(d, "listUsersMatchingQuals", func() ([]*okta.User, *okta.Response, error) {
+			return client.User.ListUsers(ctx, &input)
+		})
+		users, err = paginator.All(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		// Default maximum limit set as per documentation
+		// https://developer.okta.com/docs/reference/api/users/#request-parameters-3
+		return getCachedList(ctx, d, "listCachedOktaUsers", func() ([]*okta.User, error) {
+			paginator := newPaginatorV2(d, "listCachedOktaUsers", func() ([]*okta.User, *okta.Response, error) {
+				return client.User.ListUsers(ctx, &query.Params{Limit: 200})
+			})
+			return paginator.All(ctx)
+		})
+	}
+
+	// Users fetched by ID are only kept if they also match the other quals
+	var matching []*okta.User
+	for _, user := range users {
+		userName, userEmail := userLoginAndEmail(user)
+		if d.EqualsQuals["user_name"] != nil && d.EqualsQualString("user_name") != userName {
+			continue
+		}
+		if d.EqualsQuals["user_email"] != nil && d.EqualsQualString("user_email") != userEmail {
+			continue
+		}
+		matching = append(matching, user)
+	}
+	return matching, nil
+}
+
+// forEachUserConcurrently calls fn for every user, with up to
+// user_fanout_concurrency users in parallel, and logs the progress of the
+// listing every userProgressInterval users. It stops at the first error, when
+// the query is cancelled or when the limit has been hit.
+func forEachUserConcurrently(ctx context.Context, d *plugin.QueryData, name string, users []*okta.User, fn func(user *okta.User) error) error {
+	logger := plugin.Logger(ctx)
+
+	concurrency := userFanoutConcurrency(d)
+	logger.Info(name, "connection", d.Connection.Name, "users", len(users), "concurrency", concurrency)
+
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	errorCh := make(chan error, len(users))
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, user := range users {
+		if len(errorCh) > 0 || d.RowsRemaining(ctx) == 0 {
+			break
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(user *okta.User) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := fn(user); err != nil {
+				errorCh <- err
+				return
+			}
+
+			if n := done.Add(1); n%userProgressInterval == 0 {
+				logger.Info(name, "connection", d.Connection.Name, "users_done", n, "users", len(users))
+			}
+		}(user)
+	}
+	wg.Wait()
+	close(errorCh)
+
+	if err, ok := <-errorCh; ok {
+		return err
+	}
+
+	logger.Info(name, "connection", d.Connection.Name, "users_done", done.Load(), "users", len(users))
+	return nil
+}
+
+// userFanoutConcurrency returns the maximum number of users whose rows are
+// listed in parallel, as set by the user_fanout_concurrency config argument.
+func userFanoutConcurrency(d *plugin.QueryData) int {
+	if c := GetConfig(d.Connection).UserFanoutConcurrency; c != nil && *c > 0 {
+		return int(*c)
+	}
+	return defaultUserFanoutConcurrency
+}
+
+func userLoginAndEmail(user *okta.User) (string, string) {
+	if user.Profile == nil {
+		return "", ""
+	}
+	login, _ := (*user.Profile)["login"].(string)
+	email, _ := (*user.Profile)["email"].(string)
+	return login, email
+}
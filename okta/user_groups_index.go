@@ -0,0 +1,209 @@
+package okta
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/okta/okta-sdk-golang/v2/okta/query"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	// Default number of users above which the user_groups column of okta_user
+	// is served from the user groups index rather than one call per user
+	defaultUserGroupsIndexThreshold = 500
+
+	// Maximum number of groups whose members are listed in parallel while
+	// building the user groups index
+	userGroupsIndexConcurrency = 10
+
+	// Key of the user groups index in the connection cache
+	userGroupsIndexKey = "getUserGroupsIndex"
+)
+
+// userGroupsIndex maps user IDs to the groups the user is a direct member of.
+type userGroupsIndex map[string][]*okta.Group
+
+// map of the QueryData of the okta_user listings that built the user groups
+// index to the index, so that the user_groups hydrate only serves the rows of
+// those listings from it. Row hydrates are called with the QueryData of the
+// listing that streamed the row. Entries expire with the index.
+var userGroupsIndexListings sync.Map
+
+// newUserGroupsIndexTrigger returns a function to call before streaming each
+// user listed by okta_user. Once more users than the user_groups_index_threshold
+// config argument have been listed, it builds the user groups index, so that
+// the user_groups column of the remaining users is served from it.
+//
+// The index is cached for list_cache_ttl, so it is only built if the list
+// cache is enabled.
+func newUserGroupsIndexTrigger(ctx context.Context, d *plugin.QueryData) func() error {
+	threshold := userGroupsIndexThreshold(d)
+	if threshold <= 0 || listCacheTtl(d) <= 0 || !slices.Contains(d.QueryContext.Columns, "user_groups") {
+		return func() error { return nil }
+	}
+
+	var count int64
+	return func() error {
+		count++
+		if count != threshold+1 {
+			return nil
+		}
+		index, err := getUserGroupsIndex(ctx, d)
+		if err != nil {
+			return err
+		}
+		userGroupsIndexListings.Store(d, index)
+		time.AfterFunc(listCacheTtl(d), func() { userGroupsIndexListings.Delete(d) })
+		return nil
+	}
+}
+
+// listingUserGroupsIndex returns the user groups index built by the okta_user
+// listing of d, if any.
+func listingUserGroupsIndex(d *plugin.QueryData) (userGroupsIndex, bool) {
+	index, ok := userGroupsIndexListings.Load(d)
+	if !ok {
+		return nil, false
+	}
+	return index.(userGroupsIndex), true
+}
+
+// userGroupsIndexThreshold returns the number of users above which the groups
+// of users are served from the user groups index, as set by the
+// user_groups_index_threshold config argument. 0 disables the index.
+func userGroupsIndexThreshold(d *plugin.QueryData) int64 {
+	if t := GetConfig(d.Connection).UserGroupsIndexThreshold; t != nil {
+		return *t
+	}
+	return defaultUserGroupsIndexThreshold
+}
+
+// newUserGroupsLookup returns a function listing the groups a user is a direct
+// member of, for tables with rows per user. Groups are served from the user
+// groups index if there are more users than the user_groups_index_threshold
+// config argument, since listing the members of every group is faster than
+// listing the groups of every user of a large org.
+//
+// Users missing from the index, e.g. created since it was built, are looked up
+// with one call.
+func newUserGroupsLookup(ctx context.Context, d *plugin.QueryData, userCount int) (func(userId string) ([]*okta.Group, error), error) {
+	client, err := Connect(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	listGroups := func(userId string) ([]*okta.Group, error) {
+		return listOktaUserGroups(ctx, d, client, userId)
+	}
+
+	if threshold := userGroupsIndexThreshold(d); threshold > 0 && int64(userCount) > threshold {
+		index, err := getUserGroupsIndex(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		return func(userId string) ([]*okta.Group, error) {
+			if groups, ok := index[userId]; ok {
+				return groups, nil
+			}
+			return listGroups(userId)
+		}, nil
+	}
+
+	return listGroups, nil
+}
+
+// listOktaUserGroups lists the groups the user is a direct member of.
+func listOktaUserGroups(ctx context.Context, d *plugin.QueryData, client *okta.Client, userId string) ([]*okta.Group, error) {
+	paginator := newPaginatorV2(d, "listUserGroups", func() ([]*okta.Group, *okta.Response, error) {
+		return client.User.ListUserGroups(ctx, userId)
+	})
+	groups, err := paginator.All(ctx)
+	// The user may have been deleted since it was listed
+	if err != nil && isNotFoundError(err) {
+		return nil, nil
+	}
+	return groups, err
+}
+
+// getUserGroupsIndex returns the user groups index from the connection cache,
+// building it first if needed. It is cached for list_cache_ttl like the full
+// listings of users and groups.
+func getUserGroupsIndex(ctx context.Context, d *plugin.QueryData) (userGroupsIndex, error) {
+	return getCachedValue(ctx, d, userGroupsIndexKey, func() (userGroupsIndex, error) {
+		return buildUserGroupsIndex(ctx, d)
+	})
+}
+
+// buildUserGroupsIndex lists every group of the org and its members, which
+// takes one call per group instead of one call per user.
+func buildUserGroupsIndex(ctx context.Context, d *plugin.QueryData) (userGroupsIndex, error) {
+	logger := plugin.Logger(ctx)
+	client, err := Connect(ctx, d)
+	if err != nil {
+		logger.Error("getUserGroupsIndex", "connect_error", err)
+		return nil, err
+	}
+
+	// Default maximum limit set as per documentation
+	// https://developer.okta.com/docs/reference/api/groups/#list-groups
+	paginator := newPaginatorV2(d, "getUserGroupsIndex.listGroups", func() ([]*okta.Group, *okta.Response, error) {
+		return client.Group.ListGroups(ctx, &query.Params{Limit: 10000})
+	})
+	groups, err := paginator.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("getUserGroupsIndex", "connection", d.Connection.Name, "groups", len(groups))
+
+	index := userGroupsIndex{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	errorCh := make(chan error, len(groups))
+	semaphore := make(chan struct{}, userGroupsIndexConcurrency)
+
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group *okta.Group) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			paginator := newPaginatorV2(d, "getUserGroupsIndex.listGroupUsers", func() ([]*okta.User, *okta.Response, error) {
+				return client.Group.ListGroupUsers(ctx, group.Id, &query.Params{Limit: 1000})
+			})
+			err := paginator.ForEach(ctx, func(user *okta.User) bool {
+				mutex.Lock()
+				index[user.Id] = append(index[user.Id], group)
+				mutex.Unlock()
+				return true
+			})
+			// The group may have been deleted since it was listed
+			if err != nil && !isNotFoundError(err) {
+				errorCh <- err
+			}
+		}(group)
+	}
+	wg.Wait()
+	close(errorCh)
+
+	if err, ok := <-errorCh; ok {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Keep the order of the groups stable across rebuilds
+	for _, userGroups := range index {
+		sort.Slice(userGroups, func(i, j int) bool {
+			return userGroups[i].Id < userGroups[j].Id
+		})
+	}
+
+	logger.Info("getUserGroupsIndex", "connection", d.Connection.Name, "users", len(index))
+	return index, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"slices"
+	"strings"
 
 	"github.com/ettle/strcase"
 	"github.com/turbot/go-kit/types"
@@ -28,6 +29,20 @@ var (
 	}
 )
 
+// pageSize returns the number of items to request per page from a list
+// endpoint: the maximum page size of the endpoint, or the query limit if it is
+// smaller, so that queries with a small limit are served by a single request.
+//
+// A maxPageSize of 0 is for endpoints without a documented maximum, which only
+// receive a page size when the query has a limit.
+func pageSize(d *plugin.QueryData, maxPageSize int64) int64 {
+	limit := d.QueryContext.Limit
+	if limit != nil && *limit > 0 && (maxPageSize == 0 || *limit < maxPageSize) {
+		return *limit
+	}
+	return maxPageSize
+}
+
 func getListValues(listValue *proto.QualValueList) []*string {
 	values := make([]*string, 0)
 	for _, value := range listValue.Values {
@@ -38,6 +53,12 @@ func getListValues(listValue *proto.QualValueList) []*string {
 
 //// other useful functions
 
+// escapeFilterValue escapes a value for a double-quoted string literal of an
+// Okta filter expression, e.g. target.id eq "value".
+func escapeFilterValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
 func buildQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap, filterKeys []string) []string {
 	filters := []string{}
 